@@ -22,6 +22,7 @@ import (
 	"rsc.io/qr"
 
 	"github.com/ghillb/tmgc/internal/config"
+	"github.com/ghillb/tmgc/internal/output"
 	"github.com/ghillb/tmgc/internal/tgclient"
 	"github.com/ghillb/tmgc/internal/types"
 )
@@ -358,27 +359,14 @@ func printAuthStatus(ctx context.Context, rt *Runtime, b *tgclient.Bundle) error
 		result.IsBot = user.Bot
 	}
 
-	switch rt.Printer.Mode {
-	case "json":
-		return rt.Printer.JSON(result)
-	case "plain":
-		line := fmt.Sprintf("%t\t%d\t%s\t%s\t%t", result.Authorized, result.UserID, result.Username, result.Phone, result.IsBot)
-		rt.Printer.Plain([]string{line})
-	default:
-		if result.Authorized {
-			fmt.Fprintln(rt.Printer.Out)
-			rt.Printer.Table([][]string{{"AUTHORIZED", "USER", "USERNAME", "PHONE", "BOT"}, {
-				strconv.FormatBool(result.Authorized),
-				strconv.FormatInt(result.UserID, 10),
-				result.Username,
-				result.Phone,
-				strconv.FormatBool(result.IsBot),
-			}})
-		} else {
+	if rt.Printer.Mode == output.ModeHuman && rt.Printer.Template == "" {
+		if !result.Authorized {
 			rt.Printer.Logln("Not authorized. Run `tmgc auth login`.")
+			return nil
 		}
+		fmt.Fprintln(rt.Printer.Out)
 	}
-	return nil
+	return rt.Printer.Render(result)
 }
 
 func cleanupLoginFailure(ctx context.Context, rt *Runtime, b *tgclient.Bundle) {
@@ -438,20 +426,7 @@ func newAuthConfigSetCmd() *cobra.Command {
 			}
 			*rt.Config = cfg
 
-			switch rt.Printer.Mode {
-			case "json":
-				return rt.Printer.JSON(cfg)
-			case "plain":
-				line := fmt.Sprintf("%d\t%s\t%s", cfg.APIID, cfg.APIHash, displaySessionStore(cfg.SessionStore))
-				rt.Printer.Plain([]string{line})
-			default:
-				rt.Printer.Table([][]string{{"API_ID", "API_HASH", "SESSION_STORE"}, {
-					strconv.Itoa(cfg.APIID),
-					maskHash(cfg.APIHash),
-					displaySessionStore(cfg.SessionStore),
-				}})
-			}
-			return nil
+			return printConfig(rt, cfg)
 		},
 	}
 
@@ -473,25 +448,30 @@ func newAuthConfigShowCmd() *cobra.Command {
 			}
 
 			cfg := *rt.Config
-			switch rt.Printer.Mode {
-			case "json":
-				return rt.Printer.JSON(cfg)
-			case "plain":
-				line := fmt.Sprintf("%d\t%s\t%s", cfg.APIID, cfg.APIHash, displaySessionStore(cfg.SessionStore))
-				rt.Printer.Plain([]string{line})
-			default:
-				rt.Printer.Table([][]string{{"API_ID", "API_HASH", "SESSION_STORE"}, {
-					strconv.Itoa(cfg.APIID),
-					maskHash(cfg.APIHash),
-					displaySessionStore(cfg.SessionStore),
-				}})
-			}
-			return nil
+			return printConfig(rt, cfg)
 		},
 	}
 	return cmd
 }
 
+// printConfig keeps its own switch: the API hash is masked for humans only.
+func printConfig(rt *Runtime, cfg config.Config) error {
+	switch rt.Printer.Mode {
+	case output.ModeJSON:
+		return rt.Printer.JSON(cfg)
+	case output.ModePlain:
+		line := fmt.Sprintf("%d\t%s\t%s", cfg.APIID, cfg.APIHash, displaySessionStore(cfg.SessionStore))
+		rt.Printer.Plain([]string{line})
+	default:
+		rt.Printer.Table([][]string{{"API_ID", "API_HASH", "SESSION_STORE"}, {
+			strconv.Itoa(cfg.APIID),
+			maskHash(cfg.APIHash),
+			displaySessionStore(cfg.SessionStore),
+		}})
+	}
+	return nil
+}
+
 func maskHash(hash string) string {
 	if hash == "" {
 		return ""
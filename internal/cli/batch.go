@@ -0,0 +1,546 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+// batchRefPattern matches ${ref.path} where ref is an op name, a line number or "prev".
+var batchRefPattern = regexp.MustCompile(`\$\{([A-Za-z0-9_-]+)((?:\.[A-Za-z0-9_-]+)*)\}`)
+
+// batchReservedKeys are op object keys that are not passed to the command.
+var batchReservedKeys = map[string]bool{"cmd": true, "name": true}
+
+func newBatchCmd() *cobra.Command {
+	var (
+		continueOnError bool
+		parallel        int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "batch <file|->",
+		Short: "Run commands from a JSONL file on one connection",
+		Long: `Run commands from a JSONL file on one connection.
+
+Each line is an object like {"cmd":"message.send","peer":"@x","text":"hi"}.
+"cmd" is the command path with dots; positional arguments are taken from keys
+named after the command's arguments, other keys become flags. Set "name" to
+reference a result later as ${name.field}; ${prev.field} and ${<line>.field}
+work too. Results are printed as NDJSON, one line per operation.`,
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{longRunningAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if parallel < 1 {
+				return errors.New("--parallel must be >= 1")
+			}
+
+			var in io.Reader = os.Stdin
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				in = f
+			}
+			ops, err := parseBatch(in)
+			if err != nil {
+				return err
+			}
+
+			globals := inheritedFlags(cmd, "config", "profile", "timeout")
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, 0)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				runner := &batchRunner{
+					parallel:        parallel,
+					continueOnError: continueOnError,
+					exec: func(ctx context.Context, op *batchOp, fields map[string]any) (json.RawMessage, error) {
+						return execBatchOp(tgclient.WithBundle(ctx, b), globals, op, fields)
+					},
+				}
+				return runner.run(ctx, ops, func(res types.BatchResult) error {
+					return rt.Printer.JSONLine(res)
+				})
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "keep going after a failed operation")
+	cmd.Flags().IntVar(&parallel, "parallel", 1, "run up to N independent operations concurrently")
+	return cmd
+}
+
+type batchOp struct {
+	Line   int
+	Name   string
+	Cmd    string
+	Fields map[string]any
+	deps   []int
+	refs   map[string]int
+}
+
+func parseBatch(r io.Reader) ([]*batchOp, error) {
+	var ops []*batchOp
+	names := make(map[string]int)
+	lines := make(map[int]int)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		dec := json.NewDecoder(strings.NewReader(text))
+		dec.UseNumber()
+		var fields map[string]any
+		if err := dec.Decode(&fields); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+
+		op := &batchOp{Line: lineNo, Fields: fields, refs: make(map[string]int)}
+		op.Cmd, _ = fields["cmd"].(string)
+		if op.Cmd == "" {
+			return nil, fmt.Errorf("line %d: missing \"cmd\"", lineNo)
+		}
+		if name, ok := fields["name"]; ok {
+			op.Name = fmt.Sprint(name)
+			if _, dup := names[op.Name]; dup {
+				return nil, fmt.Errorf("line %d: duplicate name %q", lineNo, op.Name)
+			}
+		}
+
+		idx := len(ops)
+		seen := make(map[int]bool)
+		var refErr error
+		walkBatchStrings(fields, func(s string) {
+			for _, m := range batchRefPattern.FindAllStringSubmatch(s, -1) {
+				dep, err := resolveBatchRef(m[1], idx, names, lines)
+				if err != nil && refErr == nil {
+					refErr = err
+				}
+				if err != nil {
+					continue
+				}
+				op.refs[m[1]] = dep
+				if !seen[dep] {
+					seen[dep] = true
+					op.deps = append(op.deps, dep)
+				}
+			}
+		})
+		if refErr != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, refErr)
+		}
+		sort.Ints(op.deps)
+
+		if op.Name != "" {
+			names[op.Name] = idx
+		}
+		lines[lineNo] = idx
+		ops = append(ops, op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+func resolveBatchRef(ref string, idx int, names map[string]int, lines map[int]int) (int, error) {
+	if ref == "prev" {
+		if idx == 0 {
+			return 0, errors.New("${prev} used on the first operation")
+		}
+		return idx - 1, nil
+	}
+	if dep, ok := names[ref]; ok {
+		return dep, nil
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if dep, ok := lines[n]; ok {
+			return dep, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown reference ${%s}: refer to an earlier name or line", ref)
+}
+
+func walkBatchStrings(v any, fn func(string)) {
+	switch val := v.(type) {
+	case string:
+		fn(val)
+	case []any:
+		for _, item := range val {
+			walkBatchStrings(item, fn)
+		}
+	case map[string]any:
+		for _, item := range val {
+			walkBatchStrings(item, fn)
+		}
+	}
+}
+
+type batchRunner struct {
+	parallel        int
+	continueOnError bool
+	exec            func(ctx context.Context, op *batchOp, fields map[string]any) (json.RawMessage, error)
+}
+
+// run executes ops in order. Up to parallel ops run at once, but an op never
+// starts before the ops it references have finished. Results are emitted in
+// input order; ops not started after a failure are skipped silently.
+func (r *batchRunner) run(ctx context.Context, ops []*batchOp, emit func(types.BatchResult) error) error {
+	n := len(ops)
+	results := make([]types.BatchResult, n)
+	values := make([]any, n)
+	skipped := make([]bool, n)
+	done := make([]chan struct{}, n)
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	var (
+		stop   atomic.Bool
+		failed atomic.Int32
+	)
+	emitDone := make(chan error, 1)
+	go func() {
+		var emitErr error
+		for i := 0; i < n; i++ {
+			<-done[i]
+			if skipped[i] || emitErr != nil {
+				continue
+			}
+			emitErr = emit(results[i])
+		}
+		emitDone <- emitErr
+	}()
+
+	sem := make(chan struct{}, r.parallel)
+	for i, op := range ops {
+		for _, dep := range op.deps {
+			<-done[dep]
+		}
+		sem <- struct{}{}
+		if stop.Load() || ctx.Err() != nil {
+			skipped[i] = true
+			<-sem
+			close(done[i])
+			continue
+		}
+
+		go func(i int, op *batchOp) {
+			defer func() {
+				<-sem
+				close(done[i])
+			}()
+
+			res := types.BatchResult{Line: op.Line, Name: op.Name, Cmd: op.Cmd}
+			raw, err := r.execOp(ctx, op, results, values)
+			if err != nil {
+				res.Error = err.Error()
+				failed.Add(1)
+				if !r.continueOnError {
+					stop.Store(true)
+				}
+			} else {
+				res.OK = true
+				res.Result = raw
+				if len(raw) > 0 {
+					dec := json.NewDecoder(bytes.NewReader(raw))
+					dec.UseNumber()
+					_ = dec.Decode(&values[i])
+				}
+			}
+			results[i] = res
+		}(i, op)
+	}
+
+	if err := <-emitDone; err != nil {
+		return err
+	}
+	if n := failed.Load(); n > 0 {
+		return fmt.Errorf("batch: %d operation(s) failed", n)
+	}
+	return ctx.Err()
+}
+
+func (r *batchRunner) execOp(ctx context.Context, op *batchOp, results []types.BatchResult, values []any) (json.RawMessage, error) {
+	for _, dep := range op.deps {
+		if !results[dep].OK {
+			return nil, fmt.Errorf("depends on failed line %d", results[dep].Line)
+		}
+	}
+	fields, err := substituteBatch(op.Fields, func(ref, path string) (string, error) {
+		return lookupBatchPath(values[op.refs[ref]], path)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.exec(ctx, op, fields.(map[string]any))
+}
+
+func substituteBatch(v any, lookup func(ref, path string) (string, error)) (any, error) {
+	switch val := v.(type) {
+	case string:
+		var firstErr error
+		out := batchRefPattern.ReplaceAllStringFunc(val, func(m string) string {
+			parts := batchRefPattern.FindStringSubmatch(m)
+			s, err := lookup(parts[1], strings.TrimPrefix(parts[2], "."))
+			if err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", m, err)
+			}
+			return s
+		})
+		return out, firstErr
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			sub, err := substituteBatch(item, lookup)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = sub
+		}
+		return out, nil
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			sub, err := substituteBatch(item, lookup)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = sub
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+func lookupBatchPath(v any, path string) (string, error) {
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			switch node := v.(type) {
+			case map[string]any:
+				next, ok := node[key]
+				if !ok {
+					return "", fmt.Errorf("no field %q in result", key)
+				}
+				v = next
+			case []any:
+				i, err := strconv.Atoi(key)
+				if err != nil || i < 0 || i >= len(node) {
+					return "", fmt.Errorf("invalid index %q in result", key)
+				}
+				v = node[i]
+			default:
+				return "", fmt.Errorf("cannot select %q in result", key)
+			}
+		}
+	}
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return val, nil
+	case json.Number:
+		return val.String(), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	default:
+		data, err := json.Marshal(val)
+		return string(data), err
+	}
+}
+
+func execBatchOp(ctx context.Context, globals []string, op *batchOp, fields map[string]any) (json.RawMessage, error) {
+	path := strings.Split(op.Cmd, ".")
+	root := newRootCmd()
+	target, rest, err := root.Find(path)
+	if err != nil || target == root || len(rest) > 0 {
+		return nil, fmt.Errorf("unknown command %q", op.Cmd)
+	}
+	if !target.Runnable() {
+		return nil, fmt.Errorf("%q is not a runnable command", op.Cmd)
+	}
+	if flag, ok := target.Annotations[longRunningAnnotation]; ok {
+		if flag == "" {
+			return nil, fmt.Errorf("%s cannot run inside a batch", op.Cmd)
+		}
+		if batchFlagSet(fields, flag) {
+			return nil, fmt.Errorf("%s --%s cannot run inside a batch", op.Cmd, flag)
+		}
+	}
+	args, err := batchArgs(target, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs(append(append(append(append([]string{}, globals...), "--json"), path...), args...))
+	if err := root.ExecuteContext(ctx); err != nil {
+		return nil, err
+	}
+
+	raw := bytes.TrimSpace(out.Bytes())
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	if !json.Valid(raw) {
+		return json.Marshal(string(raw))
+	}
+	return raw, nil
+}
+
+// batchFlagSet reports whether op fields turn on the boolean flag name.
+func batchFlagSet(fields map[string]any, name string) bool {
+	for _, key := range []string{name, strings.ReplaceAll(name, "-", "_")} {
+		values, err := batchValues(fields[key])
+		if err != nil {
+			continue
+		}
+		for _, v := range values {
+			if on, err := strconv.ParseBool(v); err == nil && on {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type batchPositional struct {
+	name     string
+	variadic bool
+}
+
+// usePositionals reads argument names from a cobra Use line, e.g.
+// "send <peer> [text]" or "react <peer> <id> <emoji...>".
+func usePositionals(use string) []batchPositional {
+	fields := strings.Fields(use)
+	if len(fields) < 2 {
+		return nil
+	}
+	var out []batchPositional
+	for _, f := range fields[1:] {
+		if !strings.HasPrefix(f, "<") && !strings.HasPrefix(f, "[") {
+			continue
+		}
+		name := strings.Trim(f, "<>[]")
+		name, _, _ = strings.Cut(name, "|")
+		variadic := strings.HasSuffix(name, "...")
+		name = strings.TrimSuffix(name, "...")
+		if name == "" || strings.HasPrefix(name, "-") {
+			continue
+		}
+		out = append(out, batchPositional{name: name, variadic: variadic})
+	}
+	return out
+}
+
+// batchArgs converts op fields into command-line arguments: flags first,
+// then "--" and the positional arguments.
+func batchArgs(cmd *cobra.Command, fields map[string]any) ([]string, error) {
+	remaining := make(map[string]any, len(fields))
+	for k, v := range fields {
+		if !batchReservedKeys[k] {
+			remaining[k] = v
+		}
+	}
+
+	var positionals []string
+	missing := ""
+	for _, p := range usePositionals(cmd.Use) {
+		v, ok := remaining[p.name]
+		if !ok {
+			if missing == "" {
+				missing = p.name
+			}
+			continue
+		}
+		if missing != "" {
+			return nil, fmt.Errorf("%q requires %q", p.name, missing)
+		}
+		delete(remaining, p.name)
+
+		values, err := batchValues(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.name, err)
+		}
+		if len(values) > 1 && !p.variadic {
+			return nil, fmt.Errorf("%s: expected a single value", p.name)
+		}
+		positionals = append(positionals, values...)
+	}
+
+	keys := make([]string, 0, len(remaining))
+	for k := range remaining {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var args []string
+	for _, k := range keys {
+		values, err := batchValues(remaining[k])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		flag := "--" + strings.ReplaceAll(k, "_", "-")
+		for _, v := range values {
+			args = append(args, flag+"="+v)
+		}
+	}
+	if len(positionals) > 0 {
+		args = append(args, "--")
+		args = append(args, positionals...)
+	}
+	return args, nil
+}
+
+func batchValues(v any) ([]string, error) {
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{val}, nil
+	case json.Number:
+		return []string{val.String()}, nil
+	case bool:
+		return []string{strconv.FormatBool(val)}, nil
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			values, err := batchValues(item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, values...)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v", val)
+	}
+}
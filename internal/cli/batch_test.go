@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func TestParseBatch(t *testing.T) {
+	input := `{"cmd":"message.send","name":"hello","peer":"@x","text":"hi"}
+
+# comment
+{"cmd":"message.send","peer":"@x","text":"re ${hello.message_id}","reply":"${prev.message_id}"}
+{"cmd":"chat.history","peer":"${1.peer}"}
+`
+	ops, err := parseBatch(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ops) != 3 {
+		t.Fatalf("expected 3 ops, got %d", len(ops))
+	}
+	if ops[0].Name != "hello" || ops[1].Line != 4 || ops[2].Line != 5 {
+		t.Fatalf("unexpected ops: %+v %+v %+v", ops[0], ops[1], ops[2])
+	}
+	if !reflect.DeepEqual(ops[1].deps, []int{0}) || !reflect.DeepEqual(ops[2].deps, []int{0}) {
+		t.Fatalf("unexpected deps: %v %v", ops[1].deps, ops[2].deps)
+	}
+
+	bad := []string{
+		`{"peer":"@x"}`,
+		`{"cmd":"chat.list","peer":"${nope.id}"}`,
+		`{"cmd":"chat.list","peer":"${prev.id}"}`,
+		`{"cmd":"chat.list","name":"a"}` + "\n" + `{"cmd":"chat.list","name":"a"}`,
+		`not json`,
+	}
+	for _, in := range bad {
+		if _, err := parseBatch(strings.NewReader(in)); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
+
+func TestBatchArgs(t *testing.T) {
+	send := newMessageSendCmd()
+	args, err := batchArgs(send, map[string]any{
+		"cmd":    "message.send",
+		"name":   "x",
+		"peer":   "@x",
+		"text":   "-1 degrees",
+		"silent": true,
+		"reply":  json.Number("42"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"--reply=42", "--silent=true", "--", "@x", "-1 degrees"}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("batchArgs() = %q, want %q", args, want)
+	}
+
+	if _, err := batchArgs(send, map[string]any{"text": "hi"}); err == nil {
+		t.Fatalf("expected error for text without peer")
+	}
+	if _, err := batchArgs(send, map[string]any{"peer": []any{"a", "b"}}); err == nil {
+		t.Fatalf("expected error for multiple values on a single positional")
+	}
+}
+
+func TestUsePositionals(t *testing.T) {
+	got := usePositionals("react <peer> <id> [emoji...] [--all]")
+	want := []batchPositional{{name: "peer"}, {name: "id"}, {name: "emoji", variadic: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("usePositionals() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLookupBatchPath(t *testing.T) {
+	v := map[string]any{"message_id": json.Number("7"), "items": []any{map[string]any{"peer": "u1"}}}
+	if got, err := lookupBatchPath(v, "message_id"); err != nil || got != "7" {
+		t.Fatalf("lookupBatchPath(message_id) = %q, %v", got, err)
+	}
+	if got, err := lookupBatchPath(v, "items.0.peer"); err != nil || got != "u1" {
+		t.Fatalf("lookupBatchPath(items.0.peer) = %q, %v", got, err)
+	}
+	if _, err := lookupBatchPath(v, "missing"); err == nil {
+		t.Fatalf("expected error for missing field")
+	}
+}
+
+func TestExecBatchOpRejectsLongRunning(t *testing.T) {
+	tests := []struct {
+		cmd    string
+		fields map[string]any
+	}{
+		{cmd: "batch", fields: map[string]any{"file": "ops.jsonl"}},
+		{cmd: "shell"},
+		{cmd: "tui"},
+		{cmd: "cron.run", fields: map[string]any{"schedule.yaml": "s.yaml"}},
+		{cmd: "bot.run"},
+		{cmd: "invite.requests", fields: map[string]any{"peer": "c1", "watch": true}},
+		{cmd: "message.live-location.update", fields: map[string]any{"peer": "u1", "id": "5", "stdin": "true"}},
+	}
+	for _, tt := range tests {
+		_, err := execBatchOp(context.Background(), nil, &batchOp{Cmd: tt.cmd}, tt.fields)
+		if err == nil || !strings.Contains(err.Error(), "cannot run inside a batch") {
+			t.Fatalf("execBatchOp(%s) error = %v, want rejection", tt.cmd, err)
+		}
+	}
+
+	if batchFlagSet(map[string]any{"watch": false}, "watch") || batchFlagSet(nil, "watch") {
+		t.Fatalf("expected --watch to be off")
+	}
+}
+
+func TestBatchRunnerSubstitutesAndStops(t *testing.T) {
+	input := `{"cmd":"message.send","name":"a","peer":"@x","text":"one"}
+{"cmd":"message.send","peer":"@x","text":"reply","reply":"${a.message_id}"}
+{"cmd":"fail"}
+{"cmd":"message.send","peer":"@x","text":"never"}
+`
+	ops, err := parseBatch(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var seen []map[string]any
+	runner := &batchRunner{
+		parallel: 1,
+		exec: func(ctx context.Context, op *batchOp, fields map[string]any) (json.RawMessage, error) {
+			if op.Cmd == "fail" {
+				return nil, errors.New("boom")
+			}
+			seen = append(seen, fields)
+			return json.RawMessage(`{"ok":true,"message_id":` + string(rune('0'+len(seen))) + `}`), nil
+		},
+	}
+
+	var results []types.BatchResult
+	err = runner.run(context.Background(), ops, func(res types.BatchResult) error {
+		results = append(results, res)
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("expected batch error")
+	}
+	if len(results) != 3 || !results[0].OK || !results[1].OK || results[2].OK || results[2].Error != "boom" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if seen[1]["reply"] != "1" {
+		t.Fatalf("expected substituted reply id, got %v", seen[1]["reply"])
+	}
+}
+
+func TestBatchRunnerParallelContinue(t *testing.T) {
+	var lines []string
+	for i := 0; i < 8; i++ {
+		lines = append(lines, `{"cmd":"chat.list"}`)
+	}
+	lines[3] = `{"cmd":"fail"}`
+	ops, err := parseBatch(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	runner := &batchRunner{
+		parallel:        3,
+		continueOnError: true,
+		exec: func(ctx context.Context, op *batchOp, fields map[string]any) (json.RawMessage, error) {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}()
+			if op.Cmd == "fail" {
+				return nil, errors.New("boom")
+			}
+			return nil, nil
+		},
+	}
+
+	var results []types.BatchResult
+	err = runner.run(context.Background(), ops, func(res types.BatchResult) error {
+		results = append(results, res)
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "1 operation(s) failed") {
+		t.Fatalf("expected one failure, got %v", err)
+	}
+	if len(results) != 8 {
+		t.Fatalf("expected all 8 results, got %d", len(results))
+	}
+	for i, res := range results {
+		if res.Line != i+1 {
+			t.Fatalf("results out of order: %+v", results)
+		}
+	}
+	if peak > 3 {
+		t.Fatalf("expected at most 3 concurrent ops, got %d", peak)
+	}
+}
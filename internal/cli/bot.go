@@ -0,0 +1,670 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/telegram/peers"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+const (
+	botEventCommand  = "command"
+	botEventCallback = "callback"
+	botEventInline   = "inline"
+)
+
+func newBotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bot",
+		Short: "Run a bot account",
+	}
+
+	cmd.AddCommand(newBotRunCmd())
+	return cmd
+}
+
+func newBotRunCmd() *cobra.Command {
+	var (
+		hook        string
+		hookTimeout time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run [handlers.yaml]",
+		Short: "Answer commands, callback queries and inline queries",
+		Long: `Answer commands, callback queries and inline queries of a bot account
+(log in with auth login --bot-token).
+
+Each update is matched against the handlers in handlers.yaml, or passed to
+the --exec hook. A handler either has a static answer or runs a shell command
+that reads the update as JSON on stdin and writes its answer as JSON on
+stdout. The answer is applied: callback queries are answered (and their
+message edited), inline queries get their results, and replies are sent.
+
+Each update is printed, one JSON object per line with --json.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return fmt.Errorf("accepts at most one handlers file")
+			}
+			if (len(args) == 1) == (hook != "") {
+				return fmt.Errorf("use a handlers file or --exec")
+			}
+			return nil
+		},
+		Annotations: map[string]string{longRunningAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if hookTimeout <= 0 {
+				return fmt.Errorf("--hook-timeout must be positive")
+			}
+			handlers := []botHandler{{Name: "exec", Exec: hook}}
+			if len(args) == 1 {
+				if handlers, err = loadBotHandlers(args[0]); err != nil {
+					return err
+				}
+			}
+
+			// The connection lives until interrupted.
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, 0)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				self, err := b.Client.Self(ctx)
+				if err != nil {
+					return err
+				}
+				if !self.Bot {
+					return fmt.Errorf("bot run requires a bot account (auth login --bot-token)")
+				}
+
+				r := &botRunner{
+					rt:       rt,
+					b:        b,
+					handlers: handlers,
+					username: self.Username,
+					timeout:  hookTimeout,
+					stderr:   cmd.ErrOrStderr(),
+				}
+				r.register(ctx)
+
+				err = b.ListenUpdates(ctx)
+				r.wg.Wait()
+				if err != nil && !errors.Is(err, context.Canceled) {
+					return err
+				}
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&hook, "exec", "", "shell command that handles every update")
+	cmd.Flags().DurationVar(&hookTimeout, "hook-timeout", 10*time.Second, "kill a hook that runs longer than this")
+	return cmd
+}
+
+type botFile struct {
+	Handlers []botHandler `yaml:"handlers"`
+}
+
+type botHandler struct {
+	Name   string     `yaml:"name"`
+	On     string     `yaml:"on"`
+	Match  string     `yaml:"match"`
+	Exec   string     `yaml:"exec"`
+	Answer *botAnswer `yaml:"answer"`
+
+	match *regexp.Regexp
+	dir   string
+}
+
+// botEvent is the JSON a hook reads on stdin.
+type botEvent struct {
+	Type      string  `json:"type"`
+	QueryID   string  `json:"query_id,omitempty"`
+	From      botUser `json:"from"`
+	PeerRef   string  `json:"peer_ref,omitempty"`
+	MessageID int     `json:"message_id,omitempty"`
+	Text      string  `json:"text,omitempty"`
+	Command   string  `json:"command,omitempty"`
+	Args      string  `json:"args,omitempty"`
+	Data      string  `json:"data,omitempty"`
+	Game      string  `json:"game,omitempty"`
+	Query     string  `json:"query,omitempty"`
+	Offset    string  `json:"offset,omitempty"`
+	// InlineMessageID identifies a message sent via inline mode, whose
+	// callback queries have no peer.
+	InlineMessageID string `json:"inline_message_id,omitempty"`
+
+	peer     tg.PeerClass
+	inlineID tg.InputBotInlineMessageIDClass
+}
+
+type botUser struct {
+	PeerRef  string `json:"peer_ref"`
+	Name     string `json:"name,omitempty"`
+	Username string `json:"username,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+// botAnswer is what a hook writes on stdout, or a handler's static answer.
+type botAnswer struct {
+	Text       string      `json:"text" yaml:"text"`
+	Alert      bool        `json:"alert" yaml:"alert"`
+	URL        string      `json:"url" yaml:"url"`
+	CacheTime  int         `json:"cache_time" yaml:"cache_time"`
+	Edit       string      `json:"edit" yaml:"edit"`
+	Reply      string      `json:"reply" yaml:"reply"`
+	Buttons    []string    `json:"buttons" yaml:"buttons"`
+	Results    []botResult `json:"results" yaml:"results"`
+	NextOffset string      `json:"next_offset" yaml:"next_offset"`
+	Personal   bool        `json:"personal" yaml:"personal"`
+}
+
+type botResult struct {
+	ID          string `json:"id" yaml:"id"`
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description" yaml:"description"`
+	Text        string `json:"text" yaml:"text"`
+	URL         string `json:"url" yaml:"url"`
+}
+
+func loadBotHandlers(path string) ([]botHandler, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read handlers: %w", err)
+	}
+	return parseBotHandlers(path, data)
+}
+
+func parseBotHandlers(path string, data []byte) ([]botHandler, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var f botFile
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("parse handlers: %w", err)
+	}
+	if len(f.Handlers) == 0 {
+		return nil, fmt.Errorf("handlers file has no handlers")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range f.Handlers {
+		h := &f.Handlers[i]
+		if h.Name == "" {
+			h.Name = strconv.Itoa(i + 1)
+		}
+		switch h.On {
+		case "", botEventCommand, botEventCallback, botEventInline:
+		default:
+			return nil, fmt.Errorf("handler %s: unknown event %q (use command, callback or inline)", h.Name, h.On)
+		}
+		if (h.Exec == "") == (h.Answer == nil) {
+			return nil, fmt.Errorf("handler %s: use exec or answer", h.Name)
+		}
+		if h.Answer != nil && h.On == "" {
+			return nil, fmt.Errorf("handler %s: a static answer needs on", h.Name)
+		}
+		if h.Answer != nil {
+			if err := h.Answer.check(h.On); err != nil {
+				return nil, fmt.Errorf("handler %s: %w", h.Name, err)
+			}
+		}
+		if h.Match != "" {
+			h.match = globRegexp(h.Match)
+		}
+		h.dir = filepath.Dir(abs)
+	}
+	return f.Handlers, nil
+}
+
+// globRegexp compiles a pattern where * matches any text and ? one character.
+func globRegexp(pattern string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(pattern)
+	quoted = strings.ReplaceAll(quoted, `\*`, `.*`)
+	quoted = strings.ReplaceAll(quoted, `\?`, `.`)
+	return regexp.MustCompile(`(?s)^` + quoted + `$`)
+}
+
+func (h botHandler) matches(ev botEvent) bool {
+	if h.On != "" && h.On != ev.Type {
+		return false
+	}
+	return h.match == nil || h.match.MatchString(ev.key())
+}
+
+// key is what a handler's match is applied to.
+func (ev botEvent) key() string {
+	switch ev.Type {
+	case botEventCommand:
+		return ev.Command
+	case botEventCallback:
+		if ev.Game != "" {
+			return ev.Game
+		}
+		return ev.Data
+	default:
+		return ev.Query
+	}
+}
+
+func findBotHandler(handlers []botHandler, ev botEvent) (botHandler, bool) {
+	for _, h := range handlers {
+		if h.matches(ev) {
+			return h, true
+		}
+	}
+	return botHandler{}, false
+}
+
+// check rejects answer fields that do not apply to the event type.
+func (a botAnswer) check(eventType string) error {
+	if a.Edit != "" && eventType != botEventCallback {
+		return fmt.Errorf("edit only applies to callback queries")
+	}
+	if a.Reply != "" && eventType == botEventInline {
+		return fmt.Errorf("reply does not apply to inline queries")
+	}
+	if (a.Text != "" || a.Alert || a.URL != "") && eventType != botEventCallback {
+		return fmt.Errorf("text, alert and url only apply to callback queries")
+	}
+	if (len(a.Results) > 0 || a.NextOffset != "" || a.Personal) && eventType != botEventInline {
+		return fmt.Errorf("results only apply to inline queries")
+	}
+	if len(a.Buttons) > 0 && a.Edit == "" && a.Reply == "" {
+		return fmt.Errorf("buttons require edit or reply")
+	}
+	for i, res := range a.Results {
+		if res.Title == "" || res.Text == "" {
+			return fmt.Errorf("result %d: title and text are required", i+1)
+		}
+	}
+	return nil
+}
+
+// checkEvent is check for one update. Buttons of messages sent via inline
+// mode have no chat to reply in.
+func (a botAnswer) checkEvent(ev botEvent) error {
+	if err := a.check(ev.Type); err != nil {
+		return err
+	}
+	if ev.inlineID != nil && a.Reply != "" {
+		return fmt.Errorf("reply does not apply to buttons of inline messages")
+	}
+	return nil
+}
+
+func parseBotAnswer(data []byte) (botAnswer, error) {
+	var a botAnswer
+	if len(bytes.TrimSpace(data)) == 0 {
+		return a, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&a); err != nil {
+		return a, fmt.Errorf("parse hook output: %w", err)
+	}
+	return a, nil
+}
+
+// parseBotCommand splits "/name@bot args". It reports false for other text
+// and for commands addressed to another bot.
+func parseBotCommand(text, username string) (name, args string, ok bool) {
+	if !strings.HasPrefix(text, "/") {
+		return "", "", false
+	}
+	head, rest := text[1:], ""
+	if i := strings.IndexFunc(head, unicode.IsSpace); i >= 0 {
+		head, rest = head[:i], head[i:]
+	}
+	name, target, addressed := strings.Cut(head, "@")
+	if name == "" || (addressed && !strings.EqualFold(target, username)) {
+		return "", "", false
+	}
+	return strings.ToLower(name), strings.TrimSpace(rest), true
+}
+
+type botRunner struct {
+	rt       *Runtime
+	b        *tgclient.Bundle
+	handlers []botHandler
+	username string
+	timeout  time.Duration
+	stderr   io.Writer
+
+	wg sync.WaitGroup
+	mu sync.Mutex
+}
+
+func (r *botRunner) register(ctx context.Context) {
+	d := r.b.Dispatcher
+	d.OnBotCallbackQuery(func(_ context.Context, e tg.Entities, u *tg.UpdateBotCallbackQuery) error {
+		ev := botEvent{
+			Type:      botEventCallback,
+			QueryID:   strconv.FormatInt(u.QueryID, 10),
+			From:      botUserFrom(e, u.UserID),
+			MessageID: u.MsgID,
+			Data:      string(u.Data),
+			Game:      u.GameShortName,
+			peer:      u.Peer,
+		}
+		if id, ok := peerIDFromPeerClass(u.Peer); ok {
+			ev.PeerRef = peerRefFromID(id)
+		}
+		r.dispatch(ctx, ev)
+		return nil
+	})
+	d.OnInlineBotCallbackQuery(func(_ context.Context, e tg.Entities, u *tg.UpdateInlineBotCallbackQuery) error {
+		r.dispatch(ctx, botEvent{
+			Type:            botEventCallback,
+			QueryID:         strconv.FormatInt(u.QueryID, 10),
+			From:            botUserFrom(e, u.UserID),
+			Data:            string(u.Data),
+			Game:            u.GameShortName,
+			InlineMessageID: inlineMessageID(u.MsgID),
+			inlineID:        u.MsgID,
+		})
+		return nil
+	})
+	d.OnBotInlineQuery(func(_ context.Context, e tg.Entities, u *tg.UpdateBotInlineQuery) error {
+		r.dispatch(ctx, botEvent{
+			Type:    botEventInline,
+			QueryID: strconv.FormatInt(u.QueryID, 10),
+			From:    botUserFrom(e, u.UserID),
+			Query:   u.Query,
+			Offset:  u.Offset,
+		})
+		return nil
+	})
+	onMessage := func(e tg.Entities, m tg.MessageClass) {
+		msg, ok := m.(*tg.Message)
+		if !ok || msg.Out {
+			return
+		}
+		name, args, ok := parseBotCommand(msg.Message, r.username)
+		if !ok {
+			return
+		}
+		ev := botEvent{
+			Type:      botEventCommand,
+			MessageID: msg.ID,
+			Text:      msg.Message,
+			Command:   name,
+			Args:      args,
+			peer:      msg.PeerID,
+		}
+		if id, ok := peerIDFromPeerClass(msg.PeerID); ok {
+			ev.PeerRef = peerRefFromID(id)
+		}
+		from := msg.PeerID
+		if f, ok := msg.GetFromID(); ok {
+			from = f
+		}
+		if u, ok := from.(*tg.PeerUser); ok {
+			ev.From = botUserFrom(e, u.UserID)
+		}
+		r.dispatch(ctx, ev)
+	}
+	d.OnNewMessage(func(_ context.Context, e tg.Entities, u *tg.UpdateNewMessage) error {
+		onMessage(e, u.Message)
+		return nil
+	})
+	d.OnNewChannelMessage(func(_ context.Context, e tg.Entities, u *tg.UpdateNewChannelMessage) error {
+		onMessage(e, u.Message)
+		return nil
+	})
+}
+
+func botUserFrom(e tg.Entities, userID int64) botUser {
+	user := botUser{PeerRef: userRef(userID)}
+	if u, ok := e.Users[userID]; ok {
+		user.Name = strings.TrimSpace(u.FirstName + " " + u.LastName)
+		user.Username = u.Username
+		user.Language = u.LangCode
+	}
+	return user
+}
+
+// dispatch handles an update in the background so a slow hook does not hold
+// up other updates.
+func (r *botRunner) dispatch(ctx context.Context, ev botEvent) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		item := types.BotUpdate{
+			Type:      ev.Type,
+			PeerRef:   ev.PeerRef,
+			From:      ev.From.PeerRef,
+			MessageID: ev.MessageID,
+			Input:     ev.key(),
+		}
+		if err := r.handle(ctx, ev, &item); err != nil {
+			item.Error = err.Error()
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if err := renderStream(r.rt, item); err != nil {
+			r.rt.Printer.Logf("print update: %v\n", err)
+		}
+	}()
+}
+
+func (r *botRunner) handle(ctx context.Context, ev botEvent, item *types.BotUpdate) error {
+	var answer botAnswer
+	h, ok := findBotHandler(r.handlers, ev)
+	if ok {
+		item.Handler = h.Name
+		var err error
+		if h.Answer != nil {
+			answer = *h.Answer
+		} else {
+			answer, err = r.runHook(ctx, h, ev)
+		}
+		if err == nil {
+			err = answer.checkEvent(ev)
+		}
+		if err != nil {
+			// Stop the button's loading spinner even when the hook failed.
+			if ev.Type == botEventCallback {
+				_ = r.answerCallback(ctx, ev, botAnswer{})
+			}
+			return err
+		}
+	}
+	return r.apply(ctx, ev, answer)
+}
+
+func (r *botRunner) runHook(ctx context.Context, h botHandler, ev botEvent) (botAnswer, error) {
+	input, err := json.Marshal(ev)
+	if err != nil {
+		return botAnswer{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	c := exec.CommandContext(ctx, "sh", "-c", h.Exec)
+	c.Dir = h.dir
+	c.Env = append(os.Environ(), "TMGC_EVENT="+ev.Type)
+	c.Stdin = bytes.NewReader(input)
+	c.Stdout = &stdout
+	c.Stderr = r.stderr
+	if err := c.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return botAnswer{}, fmt.Errorf("hook timed out after %s", r.timeout)
+		}
+		return botAnswer{}, fmt.Errorf("hook: %w", err)
+	}
+	return parseBotAnswer(stdout.Bytes())
+}
+
+func (r *botRunner) apply(ctx context.Context, ev botEvent, a botAnswer) error {
+	api := r.b.Client.API()
+	switch ev.Type {
+	case botEventInline:
+		return r.answerInline(ctx, ev, a)
+	case botEventCallback:
+		if err := r.answerCallback(ctx, ev, a); err != nil {
+			return err
+		}
+	}
+	if a.Edit == "" && a.Reply == "" {
+		return nil
+	}
+
+	markup, err := buildMarkup(markupSpec{Buttons: a.Buttons})
+	if err != nil {
+		return err
+	}
+	if ev.inlineID != nil {
+		return r.editInline(ctx, ev.inlineID, a.Edit, markup)
+	}
+	peer, err := r.b.Peers.ResolvePeer(ctx, ev.peer)
+	if err != nil {
+		return err
+	}
+	if a.Edit != "" {
+		req := &tg.MessagesEditMessageRequest{Peer: peer.InputPeer(), ID: ev.MessageID}
+		req.SetMessage(a.Edit)
+		if markup != nil {
+			req.SetReplyMarkup(markup)
+		}
+		if _, err := api.MessagesEditMessage(ctx, req); err != nil && !tgerr.Is(err, "MESSAGE_NOT_MODIFIED") {
+			return err
+		}
+	}
+	if a.Reply != "" {
+		return r.reply(ctx, peer, ev, a.Reply, markup)
+	}
+	return nil
+}
+
+// editInline edits a message sent via inline mode. Such messages live on the
+// DC named in their ID, which need not be the bot's own.
+func (r *botRunner) editInline(ctx context.Context, id tg.InputBotInlineMessageIDClass, text string, markup tg.ReplyMarkupClass) error {
+	req := &tg.MessagesEditInlineBotMessageRequest{ID: id}
+	req.SetMessage(text)
+	if markup != nil {
+		req.SetReplyMarkup(markup)
+	}
+	api := r.b.Client.API()
+	if dc := id.GetDCID(); dc != r.b.Client.Config().ThisDC {
+		invoker, err := r.b.Client.DC(ctx, dc, 1)
+		if err != nil {
+			return err
+		}
+		defer invoker.Close()
+		api = tg.NewClient(invoker)
+	}
+	if _, err := api.MessagesEditInlineBotMessage(ctx, req); err != nil && !tgerr.Is(err, "MESSAGE_NOT_MODIFIED") {
+		return err
+	}
+	return nil
+}
+
+// inlineMessageID encodes an inline message ID for hooks, like the Bot API's
+// inline_message_id.
+func inlineMessageID(id tg.InputBotInlineMessageIDClass) string {
+	var buf bin.Buffer
+	if err := id.Encode(&buf); err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(buf.Buf)
+}
+
+func (r *botRunner) reply(ctx context.Context, peer peers.Peer, ev botEvent, text string, markup tg.ReplyMarkupClass) error {
+	m := outgoingMessage{Text: text, Markup: markup}
+	if ev.Type == botEventCommand {
+		m.ReplyTo = inputReplyTo(ev.MessageID, 0)
+	}
+	_, err := sendMessage(ctx, r.b.Client.API(), peer.InputPeer(), m)
+	return err
+}
+
+func (r *botRunner) answerCallback(ctx context.Context, ev botEvent, a botAnswer) error {
+	queryID, err := strconv.ParseInt(ev.QueryID, 10, 64)
+	if err != nil {
+		return err
+	}
+	req := &tg.MessagesSetBotCallbackAnswerRequest{
+		QueryID:   queryID,
+		Alert:     a.Alert,
+		CacheTime: a.CacheTime,
+	}
+	if a.Text != "" {
+		req.SetMessage(a.Text)
+	}
+	if a.URL != "" {
+		req.SetURL(a.URL)
+	}
+	_, err = r.b.Client.API().MessagesSetBotCallbackAnswer(ctx, req)
+	return err
+}
+
+func (r *botRunner) answerInline(ctx context.Context, ev botEvent, a botAnswer) error {
+	queryID, err := strconv.ParseInt(ev.QueryID, 10, 64)
+	if err != nil {
+		return err
+	}
+	req := &tg.MessagesSetInlineBotResultsRequest{
+		QueryID:   queryID,
+		Private:   a.Personal,
+		CacheTime: a.CacheTime,
+		Results:   inlineResults(a.Results),
+	}
+	if a.NextOffset != "" {
+		req.SetNextOffset(a.NextOffset)
+	}
+	_, err = r.b.Client.API().MessagesSetInlineBotResults(ctx, req)
+	return err
+}
+
+// inlineResults turns results into articles that send their text when
+// chosen. Results without an id are numbered.
+func inlineResults(results []botResult) []tg.InputBotInlineResultClass {
+	out := make([]tg.InputBotInlineResultClass, 0, len(results))
+	for i, res := range results {
+		item := &tg.InputBotInlineResult{
+			ID:          res.ID,
+			Type:        "article",
+			SendMessage: &tg.InputBotInlineMessageText{Message: res.Text},
+		}
+		if item.ID == "" {
+			item.ID = strconv.Itoa(i + 1)
+		}
+		item.SetTitle(res.Title)
+		if res.Description != "" {
+			item.SetDescription(res.Description)
+		}
+		if res.URL != "" {
+			item.SetURL(res.URL)
+		}
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+)
+
+func TestParseBotHandlers(t *testing.T) {
+	handlers, err := parseBotHandlers("bot/handlers.yaml", []byte(`
+handlers:
+  - name: start
+    on: command
+    match: start
+    answer:
+      reply: "Hi!"
+  - on: callback
+    match: "approve_*"
+    exec: ./approve.sh
+  - exec: ./fallback.sh
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(handlers) != 3 || handlers[1].Name != "2" || handlers[0].Answer.Reply != "Hi!" {
+		t.Fatalf("unexpected handlers %+v", handlers)
+	}
+
+	cases := []struct {
+		ev   botEvent
+		want string
+	}{
+		{botEvent{Type: botEventCommand, Command: "start"}, "start"},
+		{botEvent{Type: botEventCallback, Data: "approve_42"}, "2"},
+		{botEvent{Type: botEventCallback, Data: "reject_42"}, "3"},
+		{botEvent{Type: botEventInline, Query: "approve_1"}, "3"},
+	}
+	for _, tc := range cases {
+		h, ok := findBotHandler(handlers, tc.ev)
+		if !ok || h.Name != tc.want {
+			t.Fatalf("%+v: expected handler %s, got %q", tc.ev, tc.want, h.Name)
+		}
+	}
+
+	errCases := map[string]string{
+		"empty":         `handlers: []`,
+		"unknown event": "handlers:\n  - on: message\n    exec: x",
+		"both":          "handlers:\n  - on: command\n    exec: x\n    answer: {reply: hi}",
+		"neither":       "handlers:\n  - on: command",
+		"answer no on":  "handlers:\n  - answer: {reply: hi}",
+		"bad answer":    "handlers:\n  - on: command\n    answer: {edit: hi}",
+		"unknown field": "handlers:\n  - on: command\n    run: x",
+	}
+	for name, data := range errCases {
+		if _, err := parseBotHandlers("handlers.yaml", []byte(data)); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestGlobRegexp(t *testing.T) {
+	re := globRegexp("approve_*.v?")
+	for value, want := range map[string]bool{
+		"approve_1.v2":     true,
+		"approve_a/b.v3":   true,
+		"approve_1.v":      false,
+		"xapprove_1.v2":    false,
+		"approve_1.v2 end": false,
+	} {
+		if got := re.MatchString(value); got != want {
+			t.Fatalf("%s: expected %v, got %v", value, want, got)
+		}
+	}
+}
+
+func TestParseBotCommand(t *testing.T) {
+	cases := []struct {
+		text       string
+		name, args string
+		ok         bool
+	}{
+		{"/start", "start", "", true},
+		{"/Start@MyBot  deep link", "start", "deep link", true},
+		{"/help\nmore", "help", "more", true},
+		{"/start@OtherBot", "", "", false},
+		{"hello /start", "", "", false},
+		{"/", "", "", false},
+	}
+	for _, tc := range cases {
+		name, args, ok := parseBotCommand(tc.text, "mybot")
+		if name != tc.name || args != tc.args || ok != tc.ok {
+			t.Fatalf("%q: got %q, %q, %v", tc.text, name, args, ok)
+		}
+	}
+}
+
+func TestBotAnswer(t *testing.T) {
+	a, err := parseBotAnswer([]byte(`{"text":"Done","edit":"Approved","buttons":["Undo=cb:undo"]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := a.check(botEventCallback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := a.check(botEventCommand); err == nil {
+		t.Fatalf("expected edit to be rejected for commands")
+	}
+
+	if a, err := parseBotAnswer([]byte("  \n")); err != nil || a.Text != "" {
+		t.Fatalf("expected empty answer, got %+v, %v", a, err)
+	}
+	if _, err := parseBotAnswer([]byte(`{"txt":"typo"}`)); err == nil {
+		t.Fatalf("expected error for unknown field")
+	}
+
+	inline := botAnswer{Results: []botResult{{Title: "Cat", Text: "🐈"}}}
+	if err := inline.check(botEventInline); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := (botAnswer{Results: []botResult{{Title: "Cat"}}}).check(botEventInline); err == nil {
+		t.Fatalf("expected error for result without text")
+	}
+	if err := (botAnswer{Buttons: []string{"A=cb:a"}}).check(botEventCommand); err == nil {
+		t.Fatalf("expected error for buttons without reply")
+	}
+	results := inlineResults(inline.Results)
+	if len(results) != 1 {
+		t.Fatalf("expected one result, got %d", len(results))
+	}
+}
+
+func TestBotAnswerInlineMessage(t *testing.T) {
+	id := &tg.InputBotInlineMessageID64{DCID: 2, OwnerID: 42, ID: 7, AccessHash: 99}
+	ev := botEvent{Type: botEventCallback, InlineMessageID: inlineMessageID(id), inlineID: id}
+
+	if err := (botAnswer{Text: "Done", Edit: "Approved"}).checkEvent(ev); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := (botAnswer{Reply: "Approved"}).checkEvent(ev); err == nil {
+		t.Fatalf("expected reply to be rejected for inline messages")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(ev.InlineMessageID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := tg.DecodeInputBotInlineMessageID(&bin.Buffer{Buf: data})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, tg.InputBotInlineMessageIDClass(id)) {
+		t.Fatalf("decoded %+v, want %+v", decoded, id)
+	}
+}
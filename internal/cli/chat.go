@@ -3,13 +3,16 @@ package cli
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/gotd/td/constant"
 	"github.com/gotd/td/telegram/peers"
 	"github.com/gotd/td/tg"
 	"github.com/spf13/cobra"
 
+	"github.com/ghillb/tmgc/internal/output"
 	"github.com/ghillb/tmgc/internal/tgclient"
 	"github.com/ghillb/tmgc/internal/types"
 )
@@ -22,105 +25,112 @@ func newChatCmd() *cobra.Command {
 
 	cmd.AddCommand(newChatListCmd())
 	cmd.AddCommand(newChatHistoryCmd())
+	cmd.AddCommand(newChatPinnedCmd())
+	cmd.AddCommand(newChatReadCmd())
+	cmd.AddCommand(newChatUnreadCmd())
+	cmd.AddCommand(newChatCreateCmd())
+	cmd.AddCommand(newChatPermissionsCmd())
+	cmd.AddCommand(newChatJoinCmd())
+	cmd.AddCommand(newChatLeaveCmd())
+	cmd.AddCommand(newChatEditCmd())
+	cmd.AddCommand(newChatMuteCmd())
+	cmd.AddCommand(newChatUnmuteCmd())
+	cmd.AddCommand(newChatArchiveCmd("archive", "Move chats to the archive", archiveFolderID))
+	cmd.AddCommand(newChatArchiveCmd("unarchive", "Move chats out of the archive", 0))
+	cmd.AddCommand(newChatPinCmd("pin", "Pin a chat to the top of the chat list", true))
+	cmd.AddCommand(newChatPinCmd("unpin", "Unpin a chat", false))
+	cmd.AddCommand(newChatDeleteCmd())
+	cmd.AddCommand(newChatClearCmd())
 
 	return cmd
 }
 
 func newChatListCmd() *cobra.Command {
-	var limit int
+	var (
+		limit    int
+		all      bool
+		archived bool
+		folder   string
+		sortBy   string
+		filter   dialogFilter
+	)
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List chats",
+		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			rt, err := runtimeFrom(cmd.Context())
 			if err != nil {
 				return err
 			}
-			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
-			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
-				api := b.Client.API()
-				res, err := api.MessagesGetDialogs(ctx, &tg.MessagesGetDialogsRequest{
-					Limit:      limit,
-					OffsetPeer: &tg.InputPeerEmpty{},
-				})
-				if err != nil {
-					return err
-				}
-
-				dialogs, users, chats := extractDialogs(res)
-				if err := b.Peers.Apply(ctx, users, chats); err != nil {
-					return err
-				}
+			if err := filter.validate(); err != nil {
+				return err
+			}
+			if err := validateDialogSort(sortBy); err != nil {
+				return err
+			}
 
-				userMap, chatMap, channelMap := buildPeerMaps(users, chats)
-				items := make([]types.ChatListItem, 0, len(dialogs))
-				for _, d := range dialogs {
-					dialog, ok := d.(*tg.Dialog)
-					if !ok {
-						continue
-					}
-					peer := peerFromDialog(b.Peers, dialog.Peer, userMap, chatMap, channelMap)
-					if peer == nil {
-						continue
-					}
+			query := dialogQuery{Limit: limit, Match: filter.match}
+			if all {
+				query.Limit = 0
+			}
+			if archived {
+				query.FolderID = archiveFolderID
+			}
 
-					id := peer.TDLibPeerID()
-					item := types.ChatListItem{
-						PeerID:        int64(id),
-						PeerRef:       peerRefFromID(id),
-						PeerType:      peerTypeFromID(id),
-						Title:         peer.VisibleName(),
-						UnreadCount:   dialog.UnreadCount,
-						LastMessageID: dialog.TopMessage,
-						Pinned:        dialog.Pinned,
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				if folder != "" {
+					rules, err := loadFolderRules(ctx, b, folder)
+					if err != nil {
+						return err
 					}
-					if username, ok := peer.Username(); ok {
-						item.Username = username
+					query.Match = func(item types.ChatListItem) bool {
+						return filter.match(item) && rules.match(item)
 					}
-					items = append(items, item)
 				}
 
-				switch rt.Printer.Mode {
-				case "json":
-					return rt.Printer.JSON(items)
-				case "plain":
-					lines := make([]string, 0, len(items))
-					for _, item := range items {
-						line := fmt.Sprintf("%s\t%s\t%s\t%s\t%d\t%d\t%t",
-							item.PeerRef, item.PeerType, item.Title, item.Username, item.UnreadCount, item.LastMessageID, item.Pinned,
-						)
-						lines = append(lines, line)
+				items, err := loadDialogs(ctx, b, query)
+				if err != nil {
+					return err
+				}
+				// Folders span the main list and the archive.
+				if folder != "" && !archived && (query.Limit == 0 || len(items) < query.Limit) {
+					rest := query
+					rest.FolderID = archiveFolderID
+					if rest.Limit > 0 {
+						rest.Limit -= len(items)
 					}
-					rt.Printer.Plain(lines)
-				default:
-					rows := [][]string{{"PEER", "TYPE", "TITLE", "USERNAME", "UNREAD", "TOP", "PINNED"}}
-					for _, item := range items {
-						rows = append(rows, []string{
-							item.PeerRef,
-							item.PeerType,
-							item.Title,
-							item.Username,
-							strconv.Itoa(item.UnreadCount),
-							strconv.Itoa(item.LastMessageID),
-							strconv.FormatBool(item.Pinned),
-						})
+					more, err := loadDialogs(ctx, b, rest)
+					if err != nil {
+						return err
 					}
-					rt.Printer.Table(rows)
+					items = append(items, more...)
 				}
-				return nil
+				sortDialogs(items, sortBy)
+				return rt.Printer.Render(items)
 			})
 		},
 	}
 
 	cmd.Flags().IntVar(&limit, "limit", 50, "limit number of chats")
+	cmd.Flags().BoolVar(&all, "all", false, "list every chat (ignores --limit)")
+	cmd.Flags().BoolVar(&archived, "archived", false, "list archived chats")
+	cmd.Flags().StringVar(&folder, "folder", "", "only chats in this folder (ID or title)")
+	cmd.Flags().StringVar(&filter.Type, "type", "", "only chats of this type: user, group, channel, bot")
+	cmd.Flags().BoolVar(&filter.Unread, "unread", false, "only chats with unread messages, mentions or an unread mark")
+	cmd.Flags().BoolVar(&filter.Pinned, "pinned", false, "only pinned chats")
+	cmd.Flags().BoolVar(&filter.Muted, "muted", false, "only muted chats")
+	cmd.Flags().StringVar(&sortBy, "sort", "", "sort by date, unread or title (default: Telegram order)")
 	return cmd
 }
 
 func newChatHistoryCmd() *cobra.Command {
 	var (
-		limit int
-		since string
+		limit   int
+		since   string
+		topicID int
 	)
 
 	cmd := &cobra.Command{
@@ -144,66 +154,289 @@ func newChatHistoryCmd() *cobra.Command {
 					return err
 				}
 
-				api := b.Client.API()
-				res, err := api.MessagesGetHistory(ctx, &tg.MessagesGetHistoryRequest{
-					Peer:  peer.InputPeer(),
-					Limit: limit,
-				})
+				items, err := loadHistory(ctx, b, peer.InputPeer(), topicID, limit, cutoff)
 				if err != nil {
 					return err
 				}
-
-				messages, users, chats := extractMessages(res)
-				if err := b.Peers.Apply(ctx, users, chats); err != nil {
-					return err
-				}
-
-				items := buildMessageItems(messages, cutoff)
-				switch rt.Printer.Mode {
-				case "json":
-					return rt.Printer.JSON(items)
-				case "plain":
-					lines := make([]string, 0, len(items))
-					for _, item := range items {
-						line := fmt.Sprintf("%d\t%s\t%d\t%s",
-							item.ID,
-							item.Date.Format(time.RFC3339),
-							item.FromPeerID,
-							item.Text,
-						)
-						lines = append(lines, line)
-					}
-					rt.Printer.Plain(lines)
-				default:
-					rows := [][]string{{"ID", "DATE", "FROM", "TEXT"}}
-					for _, item := range items {
-						rows = append(rows, []string{
-							strconv.Itoa(item.ID),
-							item.Date.Format(time.RFC3339),
-							strconv.FormatInt(item.FromPeerID, 10),
-							item.Text,
-						})
-					}
-					rt.Printer.Table(rows)
-				}
-				return nil
+				return printMessages(rt, items)
 			})
 		},
 	}
 
 	cmd.Flags().IntVar(&limit, "limit", 20, "limit number of messages")
 	cmd.Flags().StringVar(&since, "since", "", "filter messages after RFC3339 timestamp")
+	cmd.Flags().IntVar(&topicID, "topic", 0, "only messages in this forum topic")
 	return cmd
 }
 
-func extractDialogs(res tg.MessagesDialogsClass) ([]tg.DialogClass, []tg.UserClass, []tg.ChatClass) {
+// loadHistory returns the newest messages of a chat, newest first, with
+// senders resolved. A thread ID (forum topic or message with replies) limits
+// the result to that thread.
+func loadHistory(ctx context.Context, b *tgclient.Bundle, peer tg.InputPeerClass, threadID, limit int, cutoff time.Time) ([]types.MessageItem, error) {
+	var (
+		res tg.MessagesMessagesClass
+		err error
+	)
+	if threadID != 0 {
+		res, err = b.Client.API().MessagesGetReplies(ctx, &tg.MessagesGetRepliesRequest{
+			Peer:  peer,
+			MsgID: threadID,
+			Limit: limit,
+		})
+	} else {
+		res, err = b.Client.API().MessagesGetHistory(ctx, &tg.MessagesGetHistoryRequest{
+			Peer:  peer,
+			Limit: limit,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	messages, users, chats := extractMessages(res)
+	if err := b.Peers.Apply(ctx, users, chats); err != nil {
+		return nil, err
+	}
+
+	items := buildMessageItems(messages, cutoff)
+	resolveSenders(ctx, b.Peers, items)
+	return items, nil
+}
+
+// archiveFolderID is the folder Telegram uses for archived chats.
+const archiveFolderID = 1
+
+type dialogQuery struct {
+	// Limit caps the number of returned dialogs; 0 loads all of them.
+	Limit    int
+	FolderID int
+	Match    func(types.ChatListItem) bool
+}
+
+// loadDialogs pages through the dialog list until the query limit is reached.
+func loadDialogs(ctx context.Context, b *tgclient.Bundle, q dialogQuery) ([]types.ChatListItem, error) {
+	req := &tg.MessagesGetDialogsRequest{
+		OffsetPeer: &tg.InputPeerEmpty{},
+	}
+	if q.FolderID != 0 {
+		req.SetFolderID(q.FolderID)
+	}
+
+	items := make([]types.ChatListItem, 0)
+	seen := make(map[int64]struct{})
+	for {
+		req.Limit = 100
+		if q.Limit > 0 && q.Match == nil {
+			req.Limit = min(q.Limit-len(items), 100)
+		}
+		res, err := b.Client.API().MessagesGetDialogs(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		dialogs, messages, users, chats := extractDialogs(res)
+		if err := b.Peers.Apply(ctx, users, chats); err != nil {
+			return nil, err
+		}
+
+		userMap, chatMap, channelMap := buildPeerMaps(users, chats)
+		lastMessages := make(map[dialogMessageKey]*tg.Message, len(messages))
+		for _, m := range messages {
+			if msg, ok := m.(*tg.Message); ok {
+				if id, ok := peerIDFromPeerClass(msg.PeerID); ok {
+					lastMessages[dialogMessageKey{int64(id), msg.ID}] = msg
+				}
+			}
+		}
+
+		var (
+			last     *tg.Dialog
+			lastPeer peers.Peer
+		)
+		for _, d := range dialogs {
+			dialog, ok := d.(*tg.Dialog)
+			if !ok {
+				continue
+			}
+			peer := peerFromDialog(b.Peers, dialog.Peer, userMap, chatMap, channelMap)
+			if peer == nil {
+				continue
+			}
+			last, lastPeer = dialog, peer
+
+			item := dialogItem(peer, dialog)
+			if _, dup := seen[item.PeerID]; dup {
+				continue
+			}
+			seen[item.PeerID] = struct{}{}
+			if msg, ok := lastMessages[dialogMessageKey{item.PeerID, dialog.TopMessage}]; ok {
+				item.LastMessageDate = time.Unix(int64(msg.Date), 0)
+				item.Preview = messagePreview(msg)
+			}
+			if q.Match != nil && !q.Match(item) {
+				continue
+			}
+			items = append(items, item)
+			if q.Limit > 0 && len(items) >= q.Limit {
+				return items, nil
+			}
+		}
+
+		slice, ok := res.(*tg.MessagesDialogsSlice)
+		if !ok || last == nil || len(dialogs) < req.Limit || len(seen) >= slice.Count {
+			return items, nil
+		}
+
+		offsetDate := 0
+		if msg, ok := lastMessages[dialogMessageKey{int64(lastPeer.TDLibPeerID()), last.TopMessage}]; ok {
+			offsetDate = msg.Date
+		}
+		if req.OffsetID == last.TopMessage && req.OffsetDate == offsetDate {
+			// No progress; stop rather than loop on the same page.
+			return items, nil
+		}
+		req.OffsetDate = offsetDate
+		req.OffsetID = last.TopMessage
+		req.OffsetPeer = lastPeer.InputPeer()
+	}
+}
+
+type dialogMessageKey struct {
+	peerID int64
+	msgID  int
+}
+
+func dialogItem(peer peers.Peer, dialog *tg.Dialog) types.ChatListItem {
+	id := peer.TDLibPeerID()
+	item := types.ChatListItem{
+		PeerID:          int64(id),
+		PeerRef:         peerRefFromID(id),
+		PeerType:        peerTypeFromID(id),
+		Kind:            peerKind(peer),
+		Title:           peer.VisibleName(),
+		UnreadCount:     dialog.UnreadCount,
+		UnreadMentions:  dialog.UnreadMentionsCount,
+		UnreadReactions: dialog.UnreadReactionsCount,
+		UnreadMark:      dialog.UnreadMark,
+		LastMessageID:   dialog.TopMessage,
+		Pinned:          dialog.Pinned,
+		Archived:        dialog.FolderID == archiveFolderID,
+	}
+	if until, ok := dialog.NotifySettings.GetMuteUntil(); ok && int64(until) > time.Now().Unix() {
+		item.Muted = true
+	}
+	if user, ok := peer.(peers.User); ok {
+		item.Contact = user.Contact()
+	}
+	if username, ok := peer.Username(); ok {
+		item.Username = username
+	}
+	return item
+}
+
+// peerKind classifies a peer as user, bot, group or channel.
+func peerKind(peer peers.Peer) string {
+	switch p := peer.(type) {
+	case peers.User:
+		if p.Raw().Bot {
+			return "bot"
+		}
+		return "user"
+	case peers.Chat:
+		return "group"
+	case peers.Channel:
+		if p.IsBroadcast() {
+			return "channel"
+		}
+		return "group"
+	default:
+		return "unknown"
+	}
+}
+
+const previewLength = 80
+
+func messagePreview(msg *tg.Message) string {
+	text := strings.Join(strings.Fields(msg.Message), " ")
+	if text == "" && msg.Media != nil {
+		return "<non-text>"
+	}
+	if runes := []rune(text); len(runes) > previewLength {
+		text = string(runes[:previewLength-1]) + "…"
+	}
+	return text
+}
+
+type dialogFilter struct {
+	Type   string
+	Unread bool
+	Pinned bool
+	Muted  bool
+}
+
+func (f dialogFilter) validate() error {
+	switch f.Type {
+	case "", "user", "group", "channel", "bot":
+		return nil
+	default:
+		return fmt.Errorf("invalid --type %q (use user, group, channel or bot)", f.Type)
+	}
+}
+
+func (f dialogFilter) match(item types.ChatListItem) bool {
+	if f.Type != "" && item.Kind != f.Type {
+		return false
+	}
+	if f.Unread && !hasUnread(item) {
+		return false
+	}
+	if f.Pinned && !item.Pinned {
+		return false
+	}
+	if f.Muted && !item.Muted {
+		return false
+	}
+	return true
+}
+
+func hasUnread(item types.ChatListItem) bool {
+	return item.UnreadCount > 0 || item.UnreadMentions > 0 || item.UnreadReactions > 0 || item.UnreadMark
+}
+
+func validateDialogSort(key string) error {
+	switch key {
+	case "", "date", "unread", "title":
+		return nil
+	default:
+		return fmt.Errorf("invalid --sort %q (use date, unread or title)", key)
+	}
+}
+
+func sortDialogs(items []types.ChatListItem, key string) {
+	switch key {
+	case "date":
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].LastMessageDate.After(items[j].LastMessageDate)
+		})
+	case "unread":
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].UnreadCount > items[j].UnreadCount
+		})
+	case "title":
+		sort.SliceStable(items, func(i, j int) bool {
+			return strings.ToLower(items[i].Title) < strings.ToLower(items[j].Title)
+		})
+	}
+}
+
+func extractDialogs(res tg.MessagesDialogsClass) ([]tg.DialogClass, []tg.MessageClass, []tg.UserClass, []tg.ChatClass) {
 	switch v := res.(type) {
 	case *tg.MessagesDialogs:
-		return v.Dialogs, v.Users, v.Chats
+		return v.Dialogs, v.Messages, v.Users, v.Chats
 	case *tg.MessagesDialogsSlice:
-		return v.Dialogs, v.Users, v.Chats
+		return v.Dialogs, v.Messages, v.Users, v.Chats
 	default:
-		return nil, nil, nil
+		return nil, nil, nil, nil
 	}
 }
 
@@ -280,6 +513,13 @@ func buildMessageItems(messages []tg.MessageClass, cutoff time.Time) []types.Mes
 					item.PeerID = int64(id)
 				}
 			}
+			if reply, ok := m.ReplyTo.(*tg.MessageReplyHeader); ok {
+				item.ReplyToID, item.TopicID = replyTarget(reply)
+			}
+			item.Reactions = reactionCounts(m)
+			for _, b := range inlineButtons(m) {
+				item.Buttons = append(item.Buttons, b.GetText())
+			}
 			if cutoff.IsZero() || item.Date.After(cutoff) {
 				if item.Text == "" && m.Media != nil {
 					item.Text = "<non-text>"
@@ -301,3 +541,91 @@ func buildMessageItems(messages []tg.MessageClass, cutoff time.Time) []types.Mes
 	}
 	return items
 }
+
+// senderPeerID returns who sent a message. Private chats and channel posts
+// omit from_peer_id; the sender is the peer itself.
+func senderPeerID(item types.MessageItem) int64 {
+	if item.FromPeerID == 0 && !item.Out {
+		return item.PeerID
+	}
+	return item.FromPeerID
+}
+
+// resolveSenders fills sender display names from the peer manager. Lookups are
+// best-effort: unresolvable senders keep only their numeric ID.
+func resolveSenders(ctx context.Context, pm *peers.Manager, items []types.MessageItem) {
+	type sender struct {
+		name     string
+		username string
+	}
+	cache := make(map[int64]sender)
+	for i := range items {
+		id := senderPeerID(items[i])
+		if id == 0 {
+			continue
+		}
+		s, ok := cache[id]
+		if !ok {
+			if peer, err := pm.ResolveTDLibID(ctx, constant.TDLibPeerID(id)); err == nil {
+				s.name = peer.VisibleName()
+				s.username, _ = peer.Username()
+			}
+			cache[id] = s
+		}
+		items[i].FromName = s.name
+		items[i].FromUser = s.username
+	}
+}
+
+func printMessages(rt *Runtime, items []types.MessageItem) error {
+	if !rt.Printer.HumanLayout() {
+		return rt.Printer.Render(items)
+	}
+
+	// History arrives newest first; a transcript reads top to bottom.
+	entries := make([]output.TranscriptEntry, 0, len(items))
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		sender := item.FromName
+		switch {
+		case item.Out:
+			sender = "You"
+		case sender == "" && senderPeerID(item) != 0:
+			sender = peerRefFromID(constant.TDLibPeerID(senderPeerID(item)))
+		case sender == "":
+			sender = "unknown"
+		}
+		reactions := make([]string, 0, len(item.Reactions))
+		for _, r := range item.Reactions {
+			reactions = append(reactions, r.String())
+		}
+		entries = append(entries, output.TranscriptEntry{
+			ID:        item.ID,
+			Date:      item.Date,
+			Sender:    sender,
+			Username:  item.FromUser,
+			Out:       item.Out,
+			Service:   item.Service,
+			Text:      item.Text,
+			Reactions: strings.Join(reactions, "  "),
+		})
+	}
+	rt.Printer.Transcript(entries)
+	return nil
+}
+
+// readHistory marks messages up to maxID as read; channels use their own RPC.
+func readHistory(ctx context.Context, api *tg.Client, peer peers.Peer, maxID int) error {
+	if ch, ok := peer.(peers.Channel); ok {
+		_, err := api.ChannelsReadHistory(ctx, &tg.ChannelsReadHistoryRequest{
+			Channel: ch.InputChannel(),
+			MaxID:   maxID,
+		})
+		return err
+	}
+	_, err := api.MessagesReadHistory(ctx, &tg.MessagesReadHistoryRequest{
+		Peer:  peer.InputPeer(),
+		MaxID: maxID,
+	})
+	return err
+}
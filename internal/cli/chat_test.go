@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func TestDialogFilter(t *testing.T) {
+	items := []types.ChatListItem{
+		{PeerRef: "u1", Kind: "user", UnreadCount: 2},
+		{PeerRef: "u2", Kind: "bot", Pinned: true},
+		{PeerRef: "ch3", Kind: "channel", Muted: true, UnreadMark: true},
+		{PeerRef: "c4", Kind: "group"},
+	}
+
+	cases := []struct {
+		name   string
+		filter dialogFilter
+		want   string
+	}{
+		{"none", dialogFilter{}, "u1,u2,ch3,c4"},
+		{"type", dialogFilter{Type: "bot"}, "u2"},
+		{"unread", dialogFilter{Unread: true}, "u1,ch3"},
+		{"pinned", dialogFilter{Pinned: true}, "u2"},
+		{"muted-unread", dialogFilter{Muted: true, Unread: true}, "ch3"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, item := range items {
+				if tc.filter.match(item) {
+					got = append(got, item.PeerRef)
+				}
+			}
+			if strings.Join(got, ",") != tc.want {
+				t.Fatalf("match() = %v, want %s", got, tc.want)
+			}
+		})
+	}
+
+	if err := (dialogFilter{Type: "supergroup"}).validate(); err == nil {
+		t.Fatalf("expected error for invalid type")
+	}
+}
+
+func TestSortDialogs(t *testing.T) {
+	now := time.Now()
+	items := []types.ChatListItem{
+		{Title: "beta", UnreadCount: 1, LastMessageDate: now.Add(-time.Hour)},
+		{Title: "Alpha", UnreadCount: 5, LastMessageDate: now.Add(-2 * time.Hour)},
+		{Title: "gamma", LastMessageDate: now},
+	}
+
+	titles := func() string {
+		out := make([]string, 0, len(items))
+		for _, item := range items {
+			out = append(out, item.Title)
+		}
+		return strings.Join(out, ",")
+	}
+
+	sortDialogs(items, "title")
+	if got := titles(); got != "Alpha,beta,gamma" {
+		t.Fatalf("sort by title = %s", got)
+	}
+	sortDialogs(items, "date")
+	if got := titles(); got != "gamma,beta,Alpha" {
+		t.Fatalf("sort by date = %s", got)
+	}
+	sortDialogs(items, "unread")
+	if got := titles(); got != "Alpha,beta,gamma" {
+		t.Fatalf("sort by unread = %s", got)
+	}
+	if err := validateDialogSort("size"); err == nil {
+		t.Fatalf("expected error for invalid sort key")
+	}
+}
+
+func TestMessagePreview(t *testing.T) {
+	if got := messagePreview(&tg.Message{Message: "hello\n  world"}); got != "hello world" {
+		t.Fatalf("messagePreview() = %q", got)
+	}
+	long := strings.Repeat("é", previewLength+5)
+	got := messagePreview(&tg.Message{Message: long})
+	if n := len([]rune(got)); n != previewLength || !strings.HasSuffix(got, "…") {
+		t.Fatalf("expected %d runes ending in ellipsis, got %d: %q", previewLength, n, got)
+	}
+	if got := messagePreview(&tg.Message{Media: &tg.MessageMediaPhoto{}}); got != "<non-text>" {
+		t.Fatalf("messagePreview() = %q", got)
+	}
+}
+
+func TestBuildMessageItemsSender(t *testing.T) {
+	items := buildMessageItems([]tg.MessageClass{
+		&tg.Message{ID: 1, PeerID: &tg.PeerUser{UserID: 7}, Message: "hi"},
+		&tg.Message{ID: 2, PeerID: &tg.PeerUser{UserID: 7}, Message: "hey", Out: true},
+	}, time.Time{})
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	// from_peer_id stays empty when Telegram omits from_id.
+	if items[0].FromPeerID != 0 || senderPeerID(items[0]) != items[0].PeerID {
+		t.Fatalf("unexpected sender for incoming message: %+v", items[0])
+	}
+	if senderPeerID(items[1]) != 0 {
+		t.Fatalf("unexpected sender for outgoing message: %+v", items[1])
+	}
+}
@@ -3,7 +3,6 @@ package cli
 import (
 	"context"
 	"errors"
-	"fmt"
 	"strings"
 
 	"github.com/gotd/td/constant"
@@ -71,24 +70,7 @@ func newContactSearchCmd() *cobra.Command {
 					}
 				}
 
-				switch rt.Printer.Mode {
-				case "json":
-					return rt.Printer.JSON(items)
-				case "plain":
-					lines := make([]string, 0, len(items))
-					for _, item := range items {
-						line := fmt.Sprintf("%s\t%s\t%s", item.DisplayName, item.Username, item.User)
-						lines = append(lines, line)
-					}
-					rt.Printer.Plain(lines)
-				default:
-					rows := [][]string{{"DISPLAY_NAME", "USERNAME", "USER"}}
-					for _, item := range items {
-						rows = append(rows, []string{item.DisplayName, item.Username, item.User})
-					}
-					rt.Printer.Table(rows)
-				}
-				return nil
+				return rt.Printer.Render(items)
 			})
 		},
 	}
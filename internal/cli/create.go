@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/telegram/peers"
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+)
+
+func newChatCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create groups, channels and forums",
+	}
+
+	cmd.AddCommand(newChatCreateGroupCmd())
+	cmd.AddCommand(newChatCreateChannelCmd("channel", "Create a broadcast channel (or a supergroup with --megagroup)"))
+	cmd.AddCommand(newChatCreateChannelCmd("forum", "Create a forum supergroup with topics"))
+
+	return cmd
+}
+
+func newChatCreateGroupCmd() *cobra.Command {
+	var members []string
+
+	cmd := &cobra.Command{
+		Use:   "group <title>",
+		Short: "Create a basic group",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				users, err := resolveUsers(ctx, b.Peers, members)
+				if err != nil {
+					return err
+				}
+
+				res, err := b.Client.API().MessagesCreateChat(ctx, &tg.MessagesCreateChatRequest{
+					Title: args[0],
+					Users: users,
+				})
+				if err != nil {
+					return err
+				}
+				warnMissingInvitees(rt, res.MissingInvitees)
+
+				peer, err := createdPeer(ctx, b, res.Updates)
+				if err != nil {
+					return err
+				}
+				return rt.Printer.Render(dialogItem(peer, &tg.Dialog{}))
+			})
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&members, "member", nil, "user to add (repeatable)")
+	return cmd
+}
+
+func newChatCreateChannelCmd(kind, short string) *cobra.Command {
+	var (
+		members   []string
+		about     string
+		megagroup bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   kind + " <title>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			req := &tg.ChannelsCreateChannelRequest{
+				Title: args[0],
+				About: about,
+			}
+			switch {
+			case kind == "forum":
+				req.Megagroup = true
+				req.Forum = true
+			case megagroup:
+				req.Megagroup = true
+			default:
+				req.Broadcast = true
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				users, err := resolveUsers(ctx, b.Peers, members)
+				if err != nil {
+					return err
+				}
+
+				updates, err := b.Client.API().ChannelsCreateChannel(ctx, req)
+				if err != nil {
+					return err
+				}
+				peer, err := createdPeer(ctx, b, updates)
+				if err != nil {
+					return err
+				}
+
+				if len(users) > 0 {
+					ch, ok := peer.(peers.Channel)
+					if !ok {
+						return fmt.Errorf("created chat is not a channel")
+					}
+					res, err := b.Client.API().ChannelsInviteToChannel(ctx, &tg.ChannelsInviteToChannelRequest{
+						Channel: ch.InputChannel(),
+						Users:   users,
+					})
+					if err != nil {
+						return fmt.Errorf("created %s but could not add members: %w", peerRefFromID(peer.TDLibPeerID()), err)
+					}
+					warnMissingInvitees(rt, res.MissingInvitees)
+				}
+				return rt.Printer.Render(dialogItem(peer, &tg.Dialog{}))
+			})
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&members, "member", nil, "user to add (repeatable)")
+	cmd.Flags().StringVar(&about, "about", "", "description")
+	if kind == "channel" {
+		cmd.Flags().BoolVar(&megagroup, "megagroup", false, "create a supergroup instead of a broadcast channel")
+	}
+	return cmd
+}
+
+func resolveUsers(ctx context.Context, pm *peers.Manager, refs []string) ([]tg.InputUserClass, error) {
+	users := make([]tg.InputUserClass, 0, len(refs))
+	for _, ref := range refs {
+		peer, err := resolvePeer(ctx, pm, ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ref, err)
+		}
+		user, ok := peer.(peers.User)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a user", ref)
+		}
+		users = append(users, user.InputUser())
+	}
+	return users, nil
+}
+
+func warnMissingInvitees(rt *Runtime, missing []tg.MissingInvitee) {
+	for _, m := range missing {
+		rt.Printer.Logf("could not add u%d (privacy settings)\n", m.UserID)
+	}
+}
+
+// createdPeer returns the chat carried by the updates of a create call.
+func createdPeer(ctx context.Context, b *tgclient.Bundle, updates tg.UpdatesClass) (peers.Peer, error) {
+	var (
+		users []tg.UserClass
+		chats []tg.ChatClass
+	)
+	switch u := updates.(type) {
+	case *tg.Updates:
+		users, chats = u.Users, u.Chats
+	case *tg.UpdatesCombined:
+		users, chats = u.Users, u.Chats
+	}
+	if err := b.Peers.Apply(ctx, users, chats); err != nil {
+		return nil, err
+	}
+
+	for _, c := range chats {
+		switch v := c.(type) {
+		case *tg.Chat:
+			return b.Peers.Chat(v), nil
+		case *tg.Channel:
+			return b.Peers.Channel(v), nil
+		}
+	}
+	return nil, fmt.Errorf("no chat in %T response", updates)
+}
@@ -0,0 +1,328 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ghillb/tmgc/internal/cron"
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func newCronCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cron",
+		Short: "Recurring messages on cron schedules",
+	}
+
+	cmd.AddCommand(newCronRunCmd())
+	return cmd
+}
+
+func newCronRunCmd() *cobra.Command {
+	var (
+		once     bool
+		maxDelay time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run <schedule.yaml>",
+		Short: "Send messages on cron schedules",
+		Long: `Send templated messages and files on cron schedules.
+
+The command keeps one connection open and sends each entry when its cron
+expression fires. With --once it sends the entries that are due and exits,
+for use from system cron. The last sent slot of every entry is kept in the
+profile directory, so a restart never sends a slot twice. Slots missed while
+not running are sent once, unless they are older than --max-delay.
+
+Each sent (or failed) entry is printed, one JSON object per line with --json.`,
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{longRunningAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			file, err := loadCronFile(args[0])
+			if err != nil {
+				return err
+			}
+			state, err := loadCronState(rt.Paths.CronPath)
+			if err != nil {
+				return err
+			}
+
+			timeout := rt.Timeout
+			if !once {
+				// The connection lives until interrupted.
+				timeout = 0
+			}
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				failed := 0
+				for {
+					now := time.Now().In(file.loc)
+					for _, e := range file.Entries {
+						key := file.key(e)
+						last, ok := state[key]
+						if !ok {
+							// First sight: only the current minute counts as due.
+							last = now.Truncate(time.Minute).Add(-time.Second)
+							state[key] = last
+							if err := saveCronState(rt.Paths.CronPath, state); err != nil {
+								return err
+							}
+						}
+						slot, due := dueSlot(e.schedule, last, now)
+						if !due {
+							continue
+						}
+
+						run := types.CronRun{Name: e.Name, Slot: slot}
+						if maxDelay > 0 && now.Sub(slot) > maxDelay {
+							run.Skipped = true
+						} else if err := sendCronEntry(ctx, b, file, e, slot, &run); err != nil {
+							run.Error = err.Error()
+							failed++
+						}
+						// Advance even on failure so a broken entry is not retried in a loop.
+						state[key] = slot
+						if err := saveCronState(rt.Paths.CronPath, state); err != nil {
+							return err
+						}
+						if err := renderStream(rt, run); err != nil {
+							return err
+						}
+					}
+					if once {
+						break
+					}
+
+					next := file.next(now)
+					if next.IsZero() {
+						return fmt.Errorf("no entry will fire again")
+					}
+					timer := time.NewTimer(time.Until(next))
+					select {
+					case <-ctx.Done():
+						timer.Stop()
+						return nil
+					case <-timer.C:
+					}
+				}
+				if failed > 0 {
+					return fmt.Errorf("%d entries failed", failed)
+				}
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&once, "once", false, "send due entries and exit (for system cron)")
+	cmd.Flags().DurationVar(&maxDelay, "max-delay", time.Hour, "skip missed slots older than this (0 sends them regardless)")
+	return cmd
+}
+
+type cronFile struct {
+	Timezone string      `yaml:"timezone"`
+	Entries  []cronEntry `yaml:"entries"`
+
+	path string
+	loc  *time.Location
+}
+
+type cronEntry struct {
+	Name   string `yaml:"name"`
+	Cron   string `yaml:"cron"`
+	Peer   string `yaml:"peer"`
+	Text   string `yaml:"text"`
+	File   string `yaml:"file"`
+	Silent bool   `yaml:"silent"`
+	Topic  int    `yaml:"topic"`
+
+	schedule cron.Schedule
+	text     *template.Template
+	file     *template.Template
+}
+
+// cronData is what text and file templates see.
+type cronData struct {
+	Name string
+	Peer string
+	Time time.Time
+}
+
+func loadCronFile(path string) (*cronFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read schedule: %w", err)
+	}
+	return parseCronFile(path, data)
+}
+
+func parseCronFile(path string, data []byte) (*cronFile, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var f cronFile
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("parse schedule: %w", err)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	f.path = abs
+	f.loc = time.Local
+	if f.Timezone != "" {
+		if f.loc, err = time.LoadLocation(f.Timezone); err != nil {
+			return nil, fmt.Errorf("unknown time zone %q", f.Timezone)
+		}
+	}
+	if len(f.Entries) == 0 {
+		return nil, fmt.Errorf("schedule has no entries")
+	}
+
+	seen := make(map[string]bool)
+	for i := range f.Entries {
+		e := &f.Entries[i]
+		if e.Name == "" {
+			return nil, fmt.Errorf("entry %d: name is required", i+1)
+		}
+		if seen[e.Name] {
+			return nil, fmt.Errorf("entry %q: duplicate name", e.Name)
+		}
+		seen[e.Name] = true
+		if e.Peer == "" {
+			return nil, fmt.Errorf("entry %q: peer is required", e.Name)
+		}
+		if strings.TrimSpace(e.Text) == "" && e.File == "" {
+			return nil, fmt.Errorf("entry %q: text or file is required", e.Name)
+		}
+		if e.schedule, err = cron.Parse(e.Cron); err != nil {
+			return nil, fmt.Errorf("entry %q: %w", e.Name, err)
+		}
+		if e.text, err = template.New(e.Name).Option("missingkey=error").Parse(e.Text); err != nil {
+			return nil, fmt.Errorf("entry %q: text: %w", e.Name, err)
+		}
+		if e.file, err = template.New(e.Name).Option("missingkey=error").Parse(e.File); err != nil {
+			return nil, fmt.Errorf("entry %q: file: %w", e.Name, err)
+		}
+	}
+	return &f, nil
+}
+
+// key identifies an entry in the state file.
+func (f *cronFile) key(e cronEntry) string {
+	return f.path + "#" + e.Name
+}
+
+// next returns the earliest upcoming slot of any entry.
+func (f *cronFile) next(now time.Time) time.Time {
+	var next time.Time
+	for _, e := range f.Entries {
+		t := e.schedule.Next(now)
+		if !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	return next
+}
+
+// dueSlot returns the latest slot in (last, now]. Several missed slots
+// collapse into one send. Slots are computed in now's time zone; last may
+// come from the state file with only a fixed offset, which would shift the
+// slots across DST changes.
+func dueSlot(s cron.Schedule, last, now time.Time) (time.Time, bool) {
+	last = last.In(now.Location())
+	var slot time.Time
+	for t := s.Next(last); !t.IsZero() && !t.After(now); t = s.Next(t) {
+		slot = t
+	}
+	return slot, !slot.IsZero()
+}
+
+func sendCronEntry(ctx context.Context, b *tgclient.Bundle, f *cronFile, e cronEntry, slot time.Time, run *types.CronRun) error {
+	data := cronData{Name: e.Name, Peer: e.Peer, Time: slot}
+	text, err := execTemplate(e.text, data)
+	if err != nil {
+		return err
+	}
+	file, err := execTemplate(e.file, data)
+	if err != nil {
+		return err
+	}
+	if file != "" && !filepath.IsAbs(file) {
+		file = filepath.Join(filepath.Dir(f.path), file)
+	}
+
+	peer, err := resolvePeer(ctx, b.Peers, e.Peer)
+	if err != nil {
+		return err
+	}
+	run.PeerRef = peerRefFromID(peer.TDLibPeerID())
+
+	updates, err := sendMessage(ctx, b.Client.API(), peer.InputPeer(), outgoingMessage{
+		Text:    text,
+		File:    file,
+		Silent:  e.Silent,
+		ReplyTo: inputReplyTo(0, e.Topic),
+	})
+	if err != nil {
+		return err
+	}
+	if id, ok := extractSentMessageID(updates); ok {
+		run.MessageID = id
+	}
+	return nil
+}
+
+func execTemplate(t *template.Template, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// loadCronState reads the last sent slot of every entry.
+func loadCronState(path string) (map[string]time.Time, error) {
+	state := make(map[string]time.Time)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("read cron state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parse cron state: %w", err)
+	}
+	return state, nil
+}
+
+func saveCronState(path string, state map[string]time.Time) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cron state: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write cron state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("rename cron state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ghillb/tmgc/internal/cron"
+)
+
+func TestParseCronFile(t *testing.T) {
+	data := []byte(`
+timezone: UTC
+entries:
+  - name: standup
+    cron: "0 9 * * mon-fri"
+    peer: "@team"
+    text: "Standup {{.Time.Format \"2006-01-02\"}} for {{.Name}}"
+    silent: true
+  - name: report
+    cron: "@daily"
+    peer: ch123
+    file: "reports/{{.Time.Format \"20060102\"}}.pdf"
+    topic: 7
+`)
+	f, err := parseCronFile("/etc/tmgc/schedule.yaml", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.Entries) != 2 || f.loc != time.UTC {
+		t.Fatalf("unexpected file: %+v", f)
+	}
+	if got := f.key(f.Entries[0]); got != "/etc/tmgc/schedule.yaml#standup" {
+		t.Fatalf("unexpected key %q", got)
+	}
+
+	slot := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	text, err := execTemplate(f.Entries[0].text, cronData{Name: "standup", Time: slot})
+	if err != nil || text != "Standup 2026-01-05 for standup" {
+		t.Fatalf("unexpected text %q (%v)", text, err)
+	}
+	file, err := execTemplate(f.Entries[1].file, cronData{Time: slot})
+	if err != nil || file != "reports/20260105.pdf" {
+		t.Fatalf("unexpected file %q (%v)", file, err)
+	}
+	if next := f.next(slot); !next.Equal(time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected next %v", next)
+	}
+}
+
+func TestParseCronFileInvalid(t *testing.T) {
+	cases := map[string]string{
+		"no entries":   "entries: []",
+		"unknown key":  "entries:\n  - name: a\n    cron: '* * * * *'\n    peer: u1\n    txt: hi",
+		"no name":      "entries:\n  - cron: '* * * * *'\n    peer: u1\n    text: hi",
+		"duplicate":    "entries:\n  - {name: a, cron: '* * * * *', peer: u1, text: hi}\n  - {name: a, cron: '* * * * *', peer: u1, text: hi}",
+		"no peer":      "entries:\n  - {name: a, cron: '* * * * *', text: hi}",
+		"no text":      "entries:\n  - {name: a, cron: '* * * * *', peer: u1}",
+		"bad cron":     "entries:\n  - {name: a, cron: '* * *', peer: u1, text: hi}",
+		"bad template": "entries:\n  - {name: a, cron: '* * * * *', peer: u1, text: '{{.Nope'}",
+		"bad zone":     "timezone: Mars/Olympus\nentries:\n  - {name: a, cron: '* * * * *', peer: u1, text: hi}",
+	}
+	for name, data := range cases {
+		if _, err := parseCronFile("s.yaml", []byte(data)); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestDueSlot(t *testing.T) {
+	s, err := cron.Parse("0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 1, 5, 12, 20, 0, 0, time.UTC)
+
+	if _, due := dueSlot(s, time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC), now); due {
+		t.Fatalf("slot already sent must not be due")
+	}
+	slot, due := dueSlot(s, time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC), now)
+	if !due || !slot.Equal(time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected missed slots to collapse into 12:00, got %v %v", slot, due)
+	}
+	if _, due := dueSlot(s, now.Truncate(time.Minute).Add(-time.Second), now); due {
+		t.Fatalf("first sight must only fire in the current minute")
+	}
+	at := time.Date(2026, 1, 5, 13, 0, 30, 0, time.UTC)
+	if _, due := dueSlot(s, at.Truncate(time.Minute).Add(-time.Second), at); !due {
+		t.Fatalf("first sight must fire in the current minute")
+	}
+}
+
+func TestDueSlotAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	s, err := cron.Parse("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The state file keeps only the CET offset of the last slot.
+	path := filepath.Join(t.TempDir(), "cron_state.json")
+	if err := saveCronState(path, map[string]time.Time{"standup": time.Date(2026, 3, 28, 9, 0, 0, 0, berlin)}); err != nil {
+		t.Fatal(err)
+	}
+	state, err := loadCronState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Clocks moved to CEST on 2026-03-29.
+	now := time.Date(2026, 3, 29, 9, 0, 30, 0, berlin)
+	slot, due := dueSlot(s, state["standup"], now)
+	if want := time.Date(2026, 3, 29, 9, 0, 0, 0, berlin); !due || !slot.Equal(want) {
+		t.Fatalf("dueSlot = %v, %v; want %v", slot, due, want)
+	}
+}
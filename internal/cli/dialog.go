@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/constant"
+	"github.com/gotd/td/telegram/peers"
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func newChatArchiveCmd(use, short string, folderID int) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use + " <peer...>",
+		Short: short,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				folderPeers := make([]tg.InputFolderPeer, 0, len(args))
+				for _, ref := range args {
+					peer, err := resolvePeer(ctx, b.Peers, ref)
+					if err != nil {
+						return fmt.Errorf("%s: %w", ref, err)
+					}
+					folderPeers = append(folderPeers, tg.InputFolderPeer{Peer: peer.InputPeer(), FolderID: folderID})
+				}
+
+				if _, err := b.Client.API().FoldersEditPeerFolders(ctx, folderPeers); err != nil {
+					return err
+				}
+				return rt.Printer.Render(types.SendResult{OK: true})
+			})
+		},
+	}
+	return cmd
+}
+
+func newChatPinCmd(use, short string, pinned bool) *cobra.Command {
+	var folderRef string
+
+	cmd := &cobra.Command{
+		Use:   use + " <peer>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+				api := b.Client.API()
+
+				if folderRef == "" {
+					if _, err := api.MessagesToggleDialogPin(ctx, &tg.MessagesToggleDialogPinRequest{
+						Pinned: pinned,
+						Peer:   &tg.InputDialogPeer{Peer: peer.InputPeer()},
+					}); err != nil {
+						return err
+					}
+					return rt.Printer.Render(types.SendResult{OK: true})
+				}
+
+				filters, err := loadFolders(ctx, api)
+				if err != nil {
+					return err
+				}
+				found, err := findFolder(filters, folderRef)
+				if err != nil {
+					return err
+				}
+				folder, ok := found.(*tg.DialogFilter)
+				if !ok {
+					return fmt.Errorf("folder %q is shared and cannot be edited here", folderRef)
+				}
+
+				setFolderPin(folder, peer.InputPeer(), peer.TDLibPeerID(), pinned)
+				if err := updateFolder(ctx, api, folder); err != nil {
+					return err
+				}
+				return rt.Printer.Render(types.SendResult{OK: true})
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&folderRef, "folder", "", "pin inside this chat folder (ID or title) instead of the main list")
+	return cmd
+}
+
+func newChatDeleteCmd() *cobra.Command {
+	var revoke bool
+
+	cmd := &cobra.Command{
+		Use:   "delete <peer>",
+		Short: "Delete a chat from the chat list",
+		Long: `Delete a chat from the chat list.
+
+Private chats: deletes the history (for both sides with --revoke).
+Groups and channels: leaves them; with --revoke the creator deletes the
+group or channel for everyone.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+				api := b.Client.API()
+
+				switch p := peer.(type) {
+				case peers.User:
+					err = deleteHistory(ctx, api, p.InputPeer(), revoke, false)
+				case peers.Chat:
+					switch {
+					case revoke && !p.Raw().Creator:
+						return fmt.Errorf("only the creator can delete %s for everyone", args[0])
+					case revoke:
+						_, err = api.MessagesDeleteChat(ctx, p.ID())
+					case p.Left():
+						err = deleteHistory(ctx, api, p.InputPeer(), false, false)
+					default:
+						err = p.LeaveAndDelete(ctx)
+					}
+				case peers.Channel:
+					switch {
+					case revoke && !p.Raw().Creator:
+						return fmt.Errorf("only the creator can delete %s for everyone", args[0])
+					case revoke:
+						err = p.Delete(ctx)
+					default:
+						err = p.Leave(ctx)
+					}
+				default:
+					return fmt.Errorf("cannot delete %s", args[0])
+				}
+				if err != nil {
+					return err
+				}
+				return rt.Printer.Render(types.SendResult{OK: true})
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&revoke, "revoke", false, "delete for everyone (private chats, or groups and channels you created)")
+	return cmd
+}
+
+func newChatClearCmd() *cobra.Command {
+	var revoke bool
+
+	cmd := &cobra.Command{
+		Use:   "clear <peer>",
+		Short: "Clear chat history but keep the chat",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+				api := b.Client.API()
+
+				if ch, ok := peer.(peers.Channel); ok {
+					if ch.IsBroadcast() {
+						return fmt.Errorf("channel history cannot be cleared; delete messages instead")
+					}
+					_, err = api.ChannelsDeleteHistory(ctx, &tg.ChannelsDeleteHistoryRequest{
+						Channel:     ch.InputChannel(),
+						ForEveryone: revoke,
+					})
+				} else {
+					err = deleteHistory(ctx, api, peer.InputPeer(), revoke, true)
+				}
+				if err != nil {
+					return err
+				}
+				return rt.Printer.Render(types.SendResult{OK: true})
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&revoke, "revoke", false, "clear for everyone (private chats; supergroups need admin rights)")
+	return cmd
+}
+
+// setFolderPin pins a chat at the top of a folder. Unpinned chats stay in
+// the folder as included chats.
+func setFolderPin(f *tg.DialogFilter, peer tg.InputPeerClass, id constant.TDLibPeerID, pinned bool) {
+	removeFolderPeer(f, id)
+	if pinned {
+		f.PinnedPeers = append([]tg.InputPeerClass{peer}, f.PinnedPeers...)
+	} else {
+		f.IncludePeers = append(f.IncludePeers, peer)
+	}
+}
+
+func deleteHistory(ctx context.Context, api *tg.Client, peer tg.InputPeerClass, revoke, justClear bool) error {
+	return drainAffected(func() (*tg.MessagesAffectedHistory, error) {
+		return api.MessagesDeleteHistory(ctx, &tg.MessagesDeleteHistoryRequest{
+			Peer:      peer,
+			Revoke:    revoke,
+			JustClear: justClear,
+		})
+	})
+}
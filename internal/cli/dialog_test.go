@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/gotd/td/constant"
+	"github.com/gotd/td/tg"
+)
+
+func TestSetFolderPin(t *testing.T) {
+	alice := &tg.InputPeerUser{UserID: 1}
+	bob := &tg.InputPeerUser{UserID: 2}
+	var aliceID constant.TDLibPeerID
+	aliceID.User(1)
+
+	f := &tg.DialogFilter{PinnedPeers: []tg.InputPeerClass{bob}, IncludePeers: []tg.InputPeerClass{alice}}
+	setFolderPin(f, alice, aliceID, true)
+	if len(f.PinnedPeers) != 2 || f.PinnedPeers[0] != alice || len(f.IncludePeers) != 0 {
+		t.Fatalf("pin: pinned=%v include=%v", f.PinnedPeers, f.IncludePeers)
+	}
+
+	setFolderPin(f, alice, aliceID, false)
+	if len(f.PinnedPeers) != 1 || f.PinnedPeers[0] != bob || len(f.IncludePeers) != 1 || f.IncludePeers[0] != alice {
+		t.Fatalf("unpin: pinned=%v include=%v", f.PinnedPeers, f.IncludePeers)
+	}
+}
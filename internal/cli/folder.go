@@ -0,0 +1,654 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gotd/td/constant"
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+// Folder IDs 0 and 1 are reserved for the main list and the archive.
+const (
+	minFolderID = 2
+	maxFolderID = 255
+)
+
+func newFolderCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "folder",
+		Short: "Chat folder operations",
+	}
+
+	cmd.AddCommand(newFolderListCmd())
+	cmd.AddCommand(newFolderShowCmd())
+	cmd.AddCommand(newFolderCreateCmd())
+	cmd.AddCommand(newFolderEditCmd())
+	cmd.AddCommand(newFolderDeleteCmd())
+	cmd.AddCommand(newFolderReorderCmd())
+
+	return cmd
+}
+
+func newFolderListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List chat folders",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				filters, err := loadFolders(ctx, b.Client.API())
+				if err != nil {
+					return err
+				}
+				self, err := b.Client.Self(ctx)
+				if err != nil {
+					return err
+				}
+
+				items := make([]types.FolderItem, 0, len(filters))
+				for _, f := range filters {
+					items = append(items, folderItem(f, self.ID))
+				}
+				return rt.Printer.Render(items)
+			})
+		},
+	}
+	return cmd
+}
+
+func newFolderShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <folder>",
+		Short: "Show the chats explicitly pinned, included or excluded in a folder",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				filters, err := loadFolders(ctx, b.Client.API())
+				if err != nil {
+					return err
+				}
+				folder, err := findFolder(filters, args[0])
+				if err != nil {
+					return err
+				}
+				self, err := b.Client.Self(ctx)
+				if err != nil {
+					return err
+				}
+
+				item := folderItem(folder, self.ID)
+				peerItems := make([]types.FolderPeerItem, 0)
+				for _, group := range []struct {
+					role string
+					refs []string
+				}{{"pinned", item.Pinned}, {"include", item.Include}, {"exclude", item.Exclude}} {
+					for _, ref := range group.refs {
+						peerItem := types.FolderPeerItem{PeerRef: ref, Role: group.role}
+						if id, ok := parsePeerRef(ref); ok {
+							if peer, err := b.Peers.ResolveTDLibID(ctx, id); err == nil {
+								peerItem.Title = peer.VisibleName()
+								peerItem.Username, _ = peer.Username()
+							}
+						}
+						peerItems = append(peerItems, peerItem)
+					}
+				}
+				return rt.Printer.Render(peerItems)
+			})
+		},
+	}
+	return cmd
+}
+
+// folderOptions holds the flags shared by `folder create` and `folder edit`.
+type folderOptions struct {
+	emoticon string
+	include  []string
+	exclude  []string
+	pin      []string
+
+	contacts        bool
+	nonContacts     bool
+	groups          bool
+	channels        bool
+	bots            bool
+	excludeMuted    bool
+	excludeRead     bool
+	excludeArchived bool
+}
+
+func (o *folderOptions) register(flags *pflag.FlagSet) {
+	flags.StringVar(&o.emoticon, "emoticon", "", "folder icon emoji")
+	flags.StringArrayVar(&o.include, "include", nil, "chat to include (repeatable)")
+	flags.StringArrayVar(&o.exclude, "exclude", nil, "chat to exclude (repeatable)")
+	flags.StringArrayVar(&o.pin, "pin", nil, "chat to pin in the folder (repeatable)")
+	flags.BoolVar(&o.contacts, "contacts", false, "include all contacts")
+	flags.BoolVar(&o.nonContacts, "non-contacts", false, "include all non-contacts")
+	flags.BoolVar(&o.groups, "groups", false, "include all groups")
+	flags.BoolVar(&o.channels, "channels", false, "include all channels")
+	flags.BoolVar(&o.bots, "bots", false, "include all bots")
+	flags.BoolVar(&o.excludeMuted, "exclude-muted", false, "exclude muted chats")
+	flags.BoolVar(&o.excludeRead, "exclude-read", false, "exclude read chats")
+	flags.BoolVar(&o.excludeArchived, "exclude-archived", false, "exclude archived chats")
+	markPeerFlags(flags, "include", "exclude", "pin")
+}
+
+// apply copies the changed flags onto f and adds the given peers.
+func (o *folderOptions) apply(ctx context.Context, b *tgclient.Bundle, flags *pflag.FlagSet, f *tg.DialogFilter) error {
+	set := func(name string, dst *bool, value bool) {
+		if flags.Changed(name) {
+			*dst = value
+		}
+	}
+	set("contacts", &f.Contacts, o.contacts)
+	set("non-contacts", &f.NonContacts, o.nonContacts)
+	set("groups", &f.Groups, o.groups)
+	set("channels", &f.Broadcasts, o.channels)
+	set("bots", &f.Bots, o.bots)
+	set("exclude-muted", &f.ExcludeMuted, o.excludeMuted)
+	set("exclude-read", &f.ExcludeRead, o.excludeRead)
+	set("exclude-archived", &f.ExcludeArchived, o.excludeArchived)
+	if flags.Changed("emoticon") {
+		f.Emoticon = o.emoticon
+	}
+
+	for _, list := range []struct {
+		refs []string
+		dst  *[]tg.InputPeerClass
+	}{{o.pin, &f.PinnedPeers}, {o.include, &f.IncludePeers}, {o.exclude, &f.ExcludePeers}} {
+		for _, ref := range list.refs {
+			peer, err := resolvePeer(ctx, b.Peers, ref)
+			if err != nil {
+				return fmt.Errorf("%s: %w", ref, err)
+			}
+			removeFolderPeer(f, peer.TDLibPeerID())
+			*list.dst = append(*list.dst, peer.InputPeer())
+		}
+	}
+	return nil
+}
+
+func newFolderCreateCmd() *cobra.Command {
+	var opts folderOptions
+
+	cmd := &cobra.Command{
+		Use:   "create <title>",
+		Short: "Create a chat folder",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				api := b.Client.API()
+				filters, err := loadFolders(ctx, api)
+				if err != nil {
+					return err
+				}
+				id, err := nextFolderID(filters)
+				if err != nil {
+					return err
+				}
+
+				folder := &tg.DialogFilter{ID: id, Title: tg.TextWithEntities{Text: args[0]}}
+				if err := opts.apply(ctx, b, cmd.Flags(), folder); err != nil {
+					return err
+				}
+				return saveFolder(ctx, b, rt, folder)
+			})
+		},
+	}
+
+	opts.register(cmd.Flags())
+	return cmd
+}
+
+func newFolderEditCmd() *cobra.Command {
+	var (
+		opts   folderOptions
+		title  string
+		remove []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "edit <folder>",
+		Short: "Edit a chat folder",
+		Long: `Edit a chat folder by ID or title.
+
+Only the given flags change; --include, --exclude and --pin add chats and
+--remove drops a chat from every list. Use --groups=false to clear a type.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				filters, err := loadFolders(ctx, b.Client.API())
+				if err != nil {
+					return err
+				}
+				found, err := findFolder(filters, args[0])
+				if err != nil {
+					return err
+				}
+				folder, ok := found.(*tg.DialogFilter)
+				if !ok {
+					return fmt.Errorf("folder %q is shared and cannot be edited here", args[0])
+				}
+
+				if title != "" {
+					folder.Title = tg.TextWithEntities{Text: title}
+				}
+				for _, ref := range remove {
+					peer, err := resolvePeer(ctx, b.Peers, ref)
+					if err != nil {
+						return fmt.Errorf("%s: %w", ref, err)
+					}
+					removeFolderPeer(folder, peer.TDLibPeerID())
+				}
+				if err := opts.apply(ctx, b, cmd.Flags(), folder); err != nil {
+					return err
+				}
+				return saveFolder(ctx, b, rt, folder)
+			})
+		},
+	}
+
+	opts.register(cmd.Flags())
+	cmd.Flags().StringVar(&title, "title", "", "new folder title")
+	cmd.Flags().StringArrayVar(&remove, "remove", nil, "chat to remove from the folder (repeatable)")
+	markPeerFlags(cmd.Flags(), "remove")
+	return cmd
+}
+
+func newFolderDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <folder>",
+		Short: "Delete a chat folder",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				api := b.Client.API()
+				filters, err := loadFolders(ctx, api)
+				if err != nil {
+					return err
+				}
+				folder, err := findFolder(filters, args[0])
+				if err != nil {
+					return err
+				}
+
+				if _, err := api.MessagesUpdateDialogFilter(ctx, &tg.MessagesUpdateDialogFilterRequest{
+					ID: folderID(folder),
+				}); err != nil {
+					return err
+				}
+				return rt.Printer.Render(types.SendResult{OK: true})
+			})
+		},
+	}
+	return cmd
+}
+
+func newFolderReorderCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reorder <folder...>",
+		Short: "Reorder chat folders",
+		Long:  "Move the given folders to the front, in order. Other folders keep their relative order.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				api := b.Client.API()
+				filters, err := loadFolders(ctx, api)
+				if err != nil {
+					return err
+				}
+
+				order, err := folderOrder(filters, args)
+				if err != nil {
+					return err
+				}
+				if _, err := api.MessagesUpdateDialogFiltersOrder(ctx, order); err != nil {
+					return err
+				}
+
+				self, err := b.Client.Self(ctx)
+				if err != nil {
+					return err
+				}
+				byID := make(map[int]tg.DialogFilterClass, len(filters))
+				for _, f := range filters {
+					byID[folderID(f)] = f
+				}
+				items := make([]types.FolderItem, 0, len(order))
+				for _, id := range order {
+					items = append(items, folderItem(byID[id], self.ID))
+				}
+				return rt.Printer.Render(items)
+			})
+		},
+	}
+	return cmd
+}
+
+func saveFolder(ctx context.Context, b *tgclient.Bundle, rt *Runtime, folder *tg.DialogFilter) error {
+	if err := updateFolder(ctx, b.Client.API(), folder); err != nil {
+		return err
+	}
+
+	self, err := b.Client.Self(ctx)
+	if err != nil {
+		return err
+	}
+	return rt.Printer.Render(folderItem(folder, self.ID))
+}
+
+func updateFolder(ctx context.Context, api *tg.Client, folder *tg.DialogFilter) error {
+	if len(folder.PinnedPeers)+len(folder.IncludePeers) == 0 &&
+		!folder.Contacts && !folder.NonContacts && !folder.Groups && !folder.Broadcasts && !folder.Bots {
+		return fmt.Errorf("a folder needs at least one chat or chat type (--include, --pin, --contacts, --groups, ...)")
+	}
+
+	req := &tg.MessagesUpdateDialogFilterRequest{ID: folder.ID}
+	req.SetFilter(folder)
+	_, err := api.MessagesUpdateDialogFilter(ctx, req)
+	return err
+}
+
+// loadFolders returns the user's folders without the built-in "All chats" entry.
+func loadFolders(ctx context.Context, api *tg.Client) ([]tg.DialogFilterClass, error) {
+	res, err := api.MessagesGetDialogFilters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filters := make([]tg.DialogFilterClass, 0, len(res.Filters))
+	for _, f := range res.Filters {
+		if _, ok := f.(*tg.DialogFilterDefault); ok {
+			continue
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// findFolder matches a folder by numeric ID or case-insensitive title.
+func findFolder(filters []tg.DialogFilterClass, ref string) (tg.DialogFilterClass, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		for _, f := range filters {
+			if folderID(f) == id {
+				return f, nil
+			}
+		}
+	}
+	for _, f := range filters {
+		if strings.EqualFold(folderTitle(f), ref) {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("folder %q not found", ref)
+}
+
+func nextFolderID(filters []tg.DialogFilterClass) (int, error) {
+	used := make(map[int]struct{}, len(filters))
+	for _, f := range filters {
+		used[folderID(f)] = struct{}{}
+	}
+	for id := minFolderID; id <= maxFolderID; id++ {
+		if _, ok := used[id]; !ok {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("no free folder id")
+}
+
+// folderOrder moves the referenced folders to the front of the current order.
+func folderOrder(filters []tg.DialogFilterClass, refs []string) ([]int, error) {
+	order := make([]int, 0, len(filters))
+	picked := make(map[int]struct{}, len(refs))
+	for _, ref := range refs {
+		f, err := findFolder(filters, ref)
+		if err != nil {
+			return nil, err
+		}
+		id := folderID(f)
+		if _, dup := picked[id]; dup {
+			return nil, fmt.Errorf("folder %q listed twice", ref)
+		}
+		picked[id] = struct{}{}
+		order = append(order, id)
+	}
+	for _, f := range filters {
+		if _, ok := picked[folderID(f)]; !ok {
+			order = append(order, folderID(f))
+		}
+	}
+	return order, nil
+}
+
+func folderID(f tg.DialogFilterClass) int {
+	switch v := f.(type) {
+	case *tg.DialogFilter:
+		return v.ID
+	case *tg.DialogFilterChatlist:
+		return v.ID
+	default:
+		return 0
+	}
+}
+
+func folderTitle(f tg.DialogFilterClass) string {
+	switch v := f.(type) {
+	case *tg.DialogFilter:
+		return v.Title.Text
+	case *tg.DialogFilterChatlist:
+		return v.Title.Text
+	default:
+		return ""
+	}
+}
+
+func folderItem(f tg.DialogFilterClass, selfID int64) types.FolderItem {
+	refs := func(list []tg.InputPeerClass) []string {
+		out := make([]string, 0, len(list))
+		for _, p := range list {
+			if id, ok := inputPeerTDLibID(p, selfID); ok {
+				out = append(out, peerRefFromID(id))
+			}
+		}
+		return out
+	}
+
+	switch v := f.(type) {
+	case *tg.DialogFilter:
+		item := types.FolderItem{
+			ID:       v.ID,
+			Title:    v.Title.Text,
+			Emoticon: v.Emoticon,
+			Pinned:   refs(v.PinnedPeers),
+			Include:  refs(v.IncludePeers),
+			Exclude:  refs(v.ExcludePeers),
+		}
+		for _, flag := range []struct {
+			name string
+			set  bool
+		}{
+			{"contacts", v.Contacts},
+			{"non_contacts", v.NonContacts},
+			{"groups", v.Groups},
+			{"channels", v.Broadcasts},
+			{"bots", v.Bots},
+			{"exclude_muted", v.ExcludeMuted},
+			{"exclude_read", v.ExcludeRead},
+			{"exclude_archived", v.ExcludeArchived},
+		} {
+			if flag.set {
+				item.Flags = append(item.Flags, flag.name)
+			}
+		}
+		return item
+	case *tg.DialogFilterChatlist:
+		return types.FolderItem{
+			ID:       v.ID,
+			Title:    v.Title.Text,
+			Emoticon: v.Emoticon,
+			Shared:   true,
+			Pinned:   refs(v.PinnedPeers),
+			Include:  refs(v.IncludePeers),
+		}
+	default:
+		return types.FolderItem{}
+	}
+}
+
+func removeFolderPeer(f *tg.DialogFilter, id constant.TDLibPeerID) {
+	keep := func(list []tg.InputPeerClass) []tg.InputPeerClass {
+		out := list[:0]
+		for _, p := range list {
+			if pid, ok := inputPeerTDLibID(p, 0); !ok || pid != id {
+				out = append(out, p)
+			}
+		}
+		return out
+	}
+	f.PinnedPeers = keep(f.PinnedPeers)
+	f.IncludePeers = keep(f.IncludePeers)
+	f.ExcludePeers = keep(f.ExcludePeers)
+}
+
+func inputPeerTDLibID(p tg.InputPeerClass, selfID int64) (constant.TDLibPeerID, bool) {
+	var id constant.TDLibPeerID
+	switch v := p.(type) {
+	case *tg.InputPeerSelf:
+		if selfID == 0 {
+			return 0, false
+		}
+		id.User(selfID)
+	case *tg.InputPeerUser:
+		id.User(v.UserID)
+	case *tg.InputPeerUserFromMessage:
+		id.User(v.UserID)
+	case *tg.InputPeerChat:
+		id.Chat(v.ChatID)
+	case *tg.InputPeerChannel:
+		id.Channel(v.ChannelID)
+	case *tg.InputPeerChannelFromMessage:
+		id.Channel(v.ChannelID)
+	default:
+		return 0, false
+	}
+	return id, true
+}
+
+func loadFolderRules(ctx context.Context, b *tgclient.Bundle, ref string) (folderRules, error) {
+	filters, err := loadFolders(ctx, b.Client.API())
+	if err != nil {
+		return folderRules{}, err
+	}
+	folder, err := findFolder(filters, ref)
+	if err != nil {
+		return folderRules{}, err
+	}
+	self, err := b.Client.Self(ctx)
+	if err != nil {
+		return folderRules{}, err
+	}
+	return newFolderRules(folder, self.ID), nil
+}
+
+// folderRules decides whether a dialog belongs to a folder.
+type folderRules struct {
+	folder  *tg.DialogFilter
+	include map[int64]struct{}
+	exclude map[int64]struct{}
+}
+
+func newFolderRules(f tg.DialogFilterClass, selfID int64) folderRules {
+	r := folderRules{include: map[int64]struct{}{}, exclude: map[int64]struct{}{}}
+	add := func(dst map[int64]struct{}, list []tg.InputPeerClass) {
+		for _, p := range list {
+			if id, ok := inputPeerTDLibID(p, selfID); ok {
+				dst[int64(id)] = struct{}{}
+			}
+		}
+	}
+	switch v := f.(type) {
+	case *tg.DialogFilter:
+		r.folder = v
+		add(r.include, v.PinnedPeers)
+		add(r.include, v.IncludePeers)
+		add(r.exclude, v.ExcludePeers)
+	case *tg.DialogFilterChatlist:
+		add(r.include, v.PinnedPeers)
+		add(r.include, v.IncludePeers)
+	}
+	return r
+}
+
+func (r folderRules) match(item types.ChatListItem) bool {
+	if _, ok := r.exclude[item.PeerID]; ok {
+		return false
+	}
+	if _, ok := r.include[item.PeerID]; ok {
+		return true
+	}
+	f := r.folder
+	if f == nil {
+		return false
+	}
+	if (f.ExcludeMuted && item.Muted) || (f.ExcludeRead && !hasUnread(item)) || (f.ExcludeArchived && item.Archived) {
+		return false
+	}
+	switch item.Kind {
+	case "user":
+		if item.Contact {
+			return f.Contacts
+		}
+		return f.NonContacts
+	case "bot":
+		return f.Bots
+	case "group":
+		return f.Groups
+	case "channel":
+		return f.Broadcasts
+	default:
+		return false
+	}
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gotd/td/constant"
+	"github.com/gotd/td/tg"
+
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func testFolders() []tg.DialogFilterClass {
+	return []tg.DialogFilterClass{
+		&tg.DialogFilter{ID: 2, Title: tg.TextWithEntities{Text: "Work"}, Groups: true, ExcludeMuted: true,
+			IncludePeers: []tg.InputPeerClass{&tg.InputPeerUser{UserID: 10}, &tg.InputPeerSelf{}},
+			ExcludePeers: []tg.InputPeerClass{&tg.InputPeerChannel{ChannelID: 20}}},
+		&tg.DialogFilterChatlist{ID: 4, Title: tg.TextWithEntities{Text: "Shared"},
+			IncludePeers: []tg.InputPeerClass{&tg.InputPeerChat{ChatID: 30}}},
+		&tg.DialogFilter{ID: 3, Title: tg.TextWithEntities{Text: "4"}},
+	}
+}
+
+func TestFindFolder(t *testing.T) {
+	filters := testFolders()
+	cases := map[string]int{"work": 2, "2": 2, "Shared": 4, "4": 4, "3": 3}
+	for ref, want := range cases {
+		f, err := findFolder(filters, ref)
+		if err != nil {
+			t.Fatalf("findFolder(%q): %v", ref, err)
+		}
+		if got := folderID(f); got != want {
+			t.Fatalf("findFolder(%q) = %d, want %d", ref, got, want)
+		}
+	}
+	if _, err := findFolder(filters, "nope"); err == nil {
+		t.Fatalf("expected error for unknown folder")
+	}
+}
+
+func TestNextFolderIDAndOrder(t *testing.T) {
+	filters := testFolders()
+	if id, err := nextFolderID(filters); err != nil || id != 5 {
+		t.Fatalf("nextFolderID() = %d, %v", id, err)
+	}
+
+	order, err := folderOrder(filters, []string{"3", "work"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(order, []int{3, 2, 4}) {
+		t.Fatalf("folderOrder() = %v", order)
+	}
+	if _, err := folderOrder(filters, []string{"work", "2"}); err == nil {
+		t.Fatalf("expected error for duplicate folder")
+	}
+}
+
+func TestFolderItem(t *testing.T) {
+	filters := testFolders()
+	got := folderItem(filters[0], 99)
+	want := types.FolderItem{
+		ID:      2,
+		Title:   "Work",
+		Flags:   []string{"groups", "exclude_muted"},
+		Pinned:  []string{},
+		Include: []string{"u10", "u99"},
+		Exclude: []string{"ch20"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("folderItem() = %+v, want %+v", got, want)
+	}
+	if shared := folderItem(filters[1], 99); !shared.Shared || !reflect.DeepEqual(shared.Include, []string{"c30"}) {
+		t.Fatalf("unexpected shared folder item: %+v", shared)
+	}
+}
+
+func TestRemoveFolderPeer(t *testing.T) {
+	f := testFolders()[0].(*tg.DialogFilter)
+	var id constant.TDLibPeerID
+	id.Channel(20)
+	removeFolderPeer(f, id)
+	if len(f.ExcludePeers) != 0 || len(f.IncludePeers) != 2 {
+		t.Fatalf("unexpected peers after remove: %+v", f)
+	}
+}
+
+func TestFolderRules(t *testing.T) {
+	rules := newFolderRules(testFolders()[0], 99)
+	id := func(ref string) int64 {
+		pid, _ := parsePeerRef(ref)
+		return int64(pid)
+	}
+
+	cases := []struct {
+		item types.ChatListItem
+		want bool
+	}{
+		{types.ChatListItem{PeerID: id("u10"), Kind: "user"}, true},
+		{types.ChatListItem{PeerID: id("u99"), Kind: "user", Muted: true}, true},
+		{types.ChatListItem{PeerID: id("ch20"), Kind: "group"}, false},
+		{types.ChatListItem{PeerID: id("ch21"), Kind: "group"}, true},
+		{types.ChatListItem{PeerID: id("ch22"), Kind: "group", Muted: true}, false},
+		{types.ChatListItem{PeerID: id("ch23"), Kind: "channel"}, false},
+		{types.ChatListItem{PeerID: id("u11"), Kind: "user", Contact: true}, false},
+	}
+	for _, tc := range cases {
+		if got := rules.match(tc.item); got != tc.want {
+			t.Fatalf("match(%+v) = %v, want %v", tc.item, got, tc.want)
+		}
+	}
+}
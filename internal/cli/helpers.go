@@ -8,6 +8,9 @@ import (
 
 	"github.com/gotd/td/constant"
 	"github.com/gotd/td/tg"
+	"github.com/spf13/pflag"
+
+	"github.com/ghillb/tmgc/internal/output"
 )
 
 func parsePeerRef(input string) (constant.TDLibPeerID, bool) {
@@ -54,6 +57,26 @@ func parsePeerRef(input string) (constant.TDLibPeerID, bool) {
 	return td, true
 }
 
+// peerFlagAnnotation marks flags whose value is a peer, so the shell can
+// substitute "." in them.
+const peerFlagAnnotation = "tmgc_peer"
+
+// longRunningAnnotation marks commands that run until interrupted and so
+// cannot be used in a batch. A non-empty value names the boolean flag that
+// makes the command long-running.
+const longRunningAnnotation = "tmgc_long_running"
+
+func markPeerFlags(flags *pflag.FlagSet, names ...string) {
+	for _, name := range names {
+		_ = flags.SetAnnotation(name, peerFlagAnnotation, []string{"true"})
+	}
+}
+
+func isPeerFlag(flag *pflag.Flag) bool {
+	_, ok := flag.Annotations[peerFlagAnnotation]
+	return ok
+}
+
 func peerRefFromID(id constant.TDLibPeerID) string {
 	switch {
 	case id.IsUser():
@@ -103,3 +126,59 @@ func parseSince(value string) (time.Time, error) {
 	}
 	return time.Parse(time.RFC3339, value)
 }
+
+func parseMessageID(value string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid message id %q", value)
+	}
+	return id, nil
+}
+
+// parseUntil reads an absolute RFC3339 time or a duration from now such as
+// "90m", "24h" or "7d". An empty value means no end time.
+func parseUntil(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return now.AddDate(0, 0, n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use RFC3339 or a duration like 24h or 7d)", value)
+}
+
+// parseFutureUntil is parseUntil for end times that must lie more than min
+// after now; name describes the value in errors. An empty value still means
+// no end time.
+func parseFutureUntil(value string, now time.Time, min time.Duration, name string) (time.Time, error) {
+	t, err := parseUntil(value, now)
+	if err != nil || t.IsZero() {
+		return t, err
+	}
+	if !t.After(now.Add(min)) {
+		if min > 0 {
+			return time.Time{}, fmt.Errorf("%s must be at least %s in the future", name, min)
+		}
+		return time.Time{}, fmt.Errorf("%s must be in the future", name)
+	}
+	return t, nil
+}
+
+// renderStream prints one item of a long-running stream. JSON output is one
+// object per line so scripts can consume it as it arrives.
+func renderStream(rt *Runtime, v any) error {
+	p := rt.Printer
+	if p.Mode == output.ModeJSON && len(p.Fields) == 0 && p.Template == "" {
+		return p.JSONLine(v)
+	}
+	return p.Render(v)
+}
@@ -0,0 +1,725 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gotd/td/telegram/peers"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func newInviteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "invite",
+		Short: "Invite links",
+	}
+
+	cmd.AddCommand(newInviteCreateCmd())
+	cmd.AddCommand(newInviteListCmd())
+	cmd.AddCommand(newInviteRevokeCmd())
+	cmd.AddCommand(newInviteImportersCmd())
+	cmd.AddCommand(newInviteCheckCmd())
+	cmd.AddCommand(newInviteRequestsCmd())
+	cmd.AddCommand(newInviteDecisionCmd("approve", "Approve pending join requests", true))
+	cmd.AddCommand(newInviteDecisionCmd("decline", "Decline pending join requests", false))
+
+	return cmd
+}
+
+func newInviteCreateCmd() *cobra.Command {
+	var (
+		expire        string
+		usageLimit    int
+		requestNeeded bool
+		title         string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create <peer>",
+		Short: "Create an invite link",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			expireAt, err := parseFutureUntil(expire, time.Now(), 0, "expiry time")
+			if err != nil {
+				return err
+			}
+			if usageLimit < 0 {
+				return fmt.Errorf("--usage-limit must be positive")
+			}
+			if usageLimit > 0 && requestNeeded {
+				return fmt.Errorf("--usage-limit cannot be combined with --request-needed")
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				req := &tg.MessagesExportChatInviteRequest{
+					Peer:          peer.InputPeer(),
+					UsageLimit:    usageLimit,
+					RequestNeeded: requestNeeded,
+					Title:         title,
+				}
+				if !expireAt.IsZero() {
+					req.ExpireDate = int(expireAt.Unix())
+				}
+				res, err := b.Client.API().MessagesExportChatInvite(ctx, req)
+				if err != nil {
+					return err
+				}
+				invite, ok := res.(*tg.ChatInviteExported)
+				if !ok {
+					return fmt.Errorf("unexpected invite %T", res)
+				}
+				return rt.Printer.Render(inviteItem(invite))
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&expire, "expire", "", "expiry (RFC3339 or duration like 24h, 7d); default never")
+	cmd.Flags().IntVar(&usageLimit, "usage-limit", 0, "maximum number of joins")
+	cmd.Flags().BoolVar(&requestNeeded, "request-needed", false, "admins must approve each join request")
+	cmd.Flags().StringVar(&title, "title", "", "label shown to admins")
+	return cmd
+}
+
+func newInviteListCmd() *cobra.Command {
+	var (
+		admin   string
+		revoked bool
+		limit   int
+		all     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list <peer>",
+		Short: "List invite links",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if all {
+				limit = 0
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				req := &tg.MessagesGetExportedChatInvitesRequest{
+					Peer:    peer.InputPeer(),
+					AdminID: &tg.InputUserSelf{},
+					Revoked: revoked,
+				}
+				if admin != "" {
+					users, err := resolveUsers(ctx, b.Peers, []string{admin})
+					if err != nil {
+						return err
+					}
+					req.AdminID = users[0]
+				}
+
+				items := make([]types.InviteItem, 0)
+				for limit == 0 || len(items) < limit {
+					req.Limit = 100
+					if limit > 0 {
+						req.Limit = min(limit-len(items), 100)
+					}
+					res, err := b.Client.API().MessagesGetExportedChatInvites(ctx, req)
+					if err != nil {
+						return err
+					}
+
+					var last *tg.ChatInviteExported
+					for _, inv := range res.Invites {
+						v, ok := inv.(*tg.ChatInviteExported)
+						if !ok {
+							continue
+						}
+						items = append(items, inviteItem(v))
+						last = v
+					}
+					if last == nil || len(items) >= res.Count {
+						break
+					}
+					req.OffsetDate, req.OffsetLink = last.Date, last.Link
+				}
+				return rt.Printer.Render(items)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&admin, "admin", "", "list links created by this admin (default: you)")
+	cmd.Flags().BoolVar(&revoked, "revoked", false, "list revoked links")
+	cmd.Flags().IntVar(&limit, "limit", 50, "limit number of links")
+	cmd.Flags().BoolVar(&all, "all", false, "list every link (ignores --limit)")
+	return cmd
+}
+
+func newInviteRevokeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke <peer> <link>",
+		Short: "Revoke an invite link",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				res, err := b.Client.API().MessagesEditExportedChatInvite(ctx, &tg.MessagesEditExportedChatInviteRequest{
+					Peer:    peer.InputPeer(),
+					Link:    args[1],
+					Revoked: true,
+				})
+				if err != nil {
+					return err
+				}
+
+				var invite tg.ExportedChatInviteClass
+				switch v := res.(type) {
+				case *tg.MessagesExportedChatInvite:
+					invite = v.Invite
+				case *tg.MessagesExportedChatInviteReplaced:
+					invite = v.Invite
+					if next, ok := v.NewInvite.(*tg.ChatInviteExported); ok {
+						rt.Printer.Logf("primary link replaced by %s\n", next.Link)
+					}
+				}
+				revoked, ok := invite.(*tg.ChatInviteExported)
+				if !ok {
+					return fmt.Errorf("unexpected invite %T", invite)
+				}
+				return rt.Printer.Render(inviteItem(revoked))
+			})
+		},
+	}
+	return cmd
+}
+
+func newInviteImportersCmd() *cobra.Command {
+	var (
+		limit int
+		all   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "importers <peer> <link>",
+		Short: "List users who joined through an invite link",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if all {
+				limit = 0
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				items, err := loadInviteImporters(ctx, b, &tg.MessagesGetChatInviteImportersRequest{
+					Peer: peer.InputPeer(),
+					Link: args[1],
+				}, limit)
+				if err != nil {
+					return err
+				}
+				return rt.Printer.Render(items)
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 100, "limit number of users")
+	cmd.Flags().BoolVar(&all, "all", false, "list every user (ignores --limit)")
+	return cmd
+}
+
+func newInviteCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check <link>",
+		Short: "Preview the chat behind an invite link without joining",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			hash, ok := inviteHash(args[0])
+			if !ok {
+				return fmt.Errorf("%s is not an invite link", args[0])
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				invite, err := b.Client.API().MessagesCheckChatInvite(ctx, hash)
+				if err != nil {
+					return err
+				}
+				preview, err := invitePreview(ctx, b.Peers, invite)
+				if err != nil {
+					return err
+				}
+				return rt.Printer.Render(preview)
+			})
+		},
+	}
+	return cmd
+}
+
+func newInviteRequestsCmd() *cobra.Command {
+	var (
+		link  string
+		limit int
+		all   bool
+		watch bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "requests <peer>",
+		Short: "List pending join requests",
+		Long: `List pending join requests.
+
+With --watch, pending requests are printed and the command keeps running,
+printing each new request as it arrives (one JSON object per line with --json).`,
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{longRunningAnnotation: "watch"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if all {
+				limit = 0
+			}
+
+			timeout := rt.Timeout
+			if watch {
+				// The connection lives until interrupted.
+				timeout = 0
+			}
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				pending := func(ctx context.Context, limit int) ([]types.InviteImporterItem, error) {
+					return loadInviteImporters(ctx, b, &tg.MessagesGetChatInviteImportersRequest{
+						Peer:      peer.InputPeer(),
+						Link:      link,
+						Requested: true,
+					}, limit)
+				}
+				items, err := pending(ctx, limit)
+				if err != nil {
+					return err
+				}
+				if !watch {
+					return rt.Printer.Render(items)
+				}
+
+				w := &joinRequestWatcher{rt: rt, seen: make(map[string]bool)}
+				for i := len(items) - 1; i >= 0; i-- {
+					if err := w.emit(items[i]); err != nil {
+						return err
+					}
+				}
+
+				d := b.Dispatcher
+				d.OnPendingJoinRequests(func(ctx context.Context, e tg.Entities, u *tg.UpdatePendingJoinRequests) error {
+					if id, ok := peerIDFromPeerClass(u.Peer); !ok || id != peer.TDLibPeerID() {
+						return nil
+					}
+					items, err := pending(ctx, 100)
+					if err != nil {
+						rt.Printer.Logf("load join requests: %v\n", err)
+						return nil
+					}
+					for i := len(items) - 1; i >= 0; i-- {
+						if err := w.emit(items[i]); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+				d.OnBotChatInviteRequester(func(ctx context.Context, e tg.Entities, u *tg.UpdateBotChatInviteRequester) error {
+					if id, ok := peerIDFromPeerClass(u.Peer); !ok || id != peer.TDLibPeerID() {
+						return nil
+					}
+					item := types.InviteImporterItem{
+						PeerRef:   userRef(u.UserID),
+						Date:      unixTime(u.Date),
+						Requested: true,
+						About:     u.About,
+					}
+					if user, err := b.Peers.ResolveUserID(ctx, u.UserID); err == nil {
+						item.Name = user.VisibleName()
+						item.Username, _ = user.Username()
+					}
+					return w.emit(item)
+				})
+
+				if err := b.ListenUpdates(ctx); err != nil && !errors.Is(err, context.Canceled) {
+					return err
+				}
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&link, "link", "", "only requests made through this invite link")
+	cmd.Flags().IntVar(&limit, "limit", 100, "limit number of requests")
+	cmd.Flags().BoolVar(&all, "all", false, "list every request (ignores --limit)")
+	cmd.Flags().BoolVar(&watch, "watch", false, "keep running and print new requests as they arrive")
+	return cmd
+}
+
+// joinRequestWatcher prints each requesting user once.
+type joinRequestWatcher struct {
+	rt   *Runtime
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func (w *joinRequestWatcher) emit(item types.InviteImporterItem) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.seen[item.PeerRef] {
+		return nil
+	}
+	w.seen[item.PeerRef] = true
+	return renderStream(w.rt, item)
+}
+
+func newInviteDecisionCmd(use, short string, approve bool) *cobra.Command {
+	var (
+		all  bool
+		link string
+	)
+
+	cmd := &cobra.Command{
+		Use:   use + " <peer> [user...]",
+		Short: short,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("peer is required")
+			}
+			if all && len(args) > 1 {
+				return fmt.Errorf("use users or --all, not both")
+			}
+			if !all && len(args) < 2 {
+				return fmt.Errorf("at least one user is required (or use --all)")
+			}
+			if link != "" && !all {
+				return fmt.Errorf("--link requires --all")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				api := b.Client.API()
+				if all {
+					if _, err := api.MessagesHideAllChatJoinRequests(ctx, &tg.MessagesHideAllChatJoinRequestsRequest{
+						Approved: approve,
+						Peer:     peer.InputPeer(),
+						Link:     link,
+					}); err != nil {
+						return err
+					}
+					return rt.Printer.Render(types.SendResult{OK: true})
+				}
+
+				users, err := resolveUsers(ctx, b.Peers, args[1:])
+				if err != nil {
+					return err
+				}
+				for i, user := range users {
+					if _, err := api.MessagesHideChatJoinRequest(ctx, &tg.MessagesHideChatJoinRequestRequest{
+						Approved: approve,
+						Peer:     peer.InputPeer(),
+						UserID:   user,
+					}); err != nil {
+						return fmt.Errorf("%s: %w", args[i+1], err)
+					}
+				}
+				return rt.Printer.Render(types.SendResult{OK: true})
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "apply to every pending request")
+	cmd.Flags().StringVar(&link, "link", "", "with --all, only requests made through this invite link")
+	return cmd
+}
+
+func newChatJoinCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "join <link|@username>",
+		Short: "Join a chat by invite link or public username",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				var (
+					res types.JoinResult
+					err error
+				)
+				if hash, ok := inviteHash(args[0]); ok {
+					res, err = joinInvite(ctx, b, hash)
+				} else {
+					res, err = joinPublic(ctx, b, args[0])
+				}
+				if err != nil {
+					return err
+				}
+				return rt.Printer.Render(res)
+			})
+		},
+	}
+	return cmd
+}
+
+func newChatLeaveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "leave <peer>",
+		Short: "Leave a group or channel",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				switch p := peer.(type) {
+				case peers.Channel:
+					err = p.Leave(ctx)
+				case peers.Chat:
+					err = p.Leave(ctx)
+				default:
+					return fmt.Errorf("%s is not a group or channel", args[0])
+				}
+				if err != nil {
+					return err
+				}
+				return rt.Printer.Render(types.SendResult{OK: true})
+			})
+		},
+	}
+	return cmd
+}
+
+func joinInvite(ctx context.Context, b *tgclient.Bundle, hash string) (types.JoinResult, error) {
+	api := b.Client.API()
+	invite, err := api.MessagesCheckChatInvite(ctx, hash)
+	if err != nil {
+		return types.JoinResult{}, err
+	}
+	preview, err := invitePreview(ctx, b.Peers, invite)
+	if err != nil {
+		return types.JoinResult{}, err
+	}
+	if preview.Joined {
+		return types.JoinResult{PeerRef: preview.PeerRef, Title: preview.Title, Status: "already"}, nil
+	}
+
+	updates, err := api.MessagesImportChatInvite(ctx, hash)
+	if tgerr.Is(err, "INVITE_REQUEST_SENT") {
+		return types.JoinResult{PeerRef: preview.PeerRef, Title: preview.Title, Status: "requested"}, nil
+	}
+	if err != nil {
+		return types.JoinResult{}, err
+	}
+	peer, err := createdPeer(ctx, b, updates)
+	if err != nil {
+		return types.JoinResult{}, err
+	}
+	return types.JoinResult{PeerRef: peerRefFromID(peer.TDLibPeerID()), Title: peer.VisibleName(), Status: "joined"}, nil
+}
+
+func joinPublic(ctx context.Context, b *tgclient.Bundle, input string) (types.JoinResult, error) {
+	peer, err := resolvePeer(ctx, b.Peers, input)
+	if err != nil {
+		return types.JoinResult{}, err
+	}
+	ch, ok := peer.(peers.Channel)
+	if !ok {
+		return types.JoinResult{}, fmt.Errorf("%s is not a public group or channel", input)
+	}
+
+	res := types.JoinResult{PeerRef: peerRefFromID(ch.TDLibPeerID()), Title: ch.VisibleName(), Status: "joined"}
+	if !ch.Left() {
+		res.Status = "already"
+		return res, nil
+	}
+	err = ch.Join(ctx)
+	if tgerr.Is(err, "INVITE_REQUEST_SENT") {
+		res.Status = "requested"
+		return res, nil
+	}
+	return res, err
+}
+
+// loadInviteImporters pages through messages.getChatInviteImporters.
+func loadInviteImporters(ctx context.Context, b *tgclient.Bundle, req *tg.MessagesGetChatInviteImportersRequest, limit int) ([]types.InviteImporterItem, error) {
+	req.OffsetUser = &tg.InputUserEmpty{}
+
+	items := make([]types.InviteImporterItem, 0)
+	for limit == 0 || len(items) < limit {
+		req.Limit = 100
+		if limit > 0 {
+			req.Limit = min(limit-len(items), 100)
+		}
+		res, err := b.Client.API().MessagesGetChatInviteImporters(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Importers) == 0 {
+			break
+		}
+		if err := b.Peers.Apply(ctx, res.Users, nil); err != nil {
+			return nil, err
+		}
+
+		names := memberNames(b.Peers, res.Users, nil)
+		for _, imp := range res.Importers {
+			item := types.InviteImporterItem{
+				PeerRef:    userRef(imp.UserID),
+				Date:       unixTime(imp.Date),
+				Requested:  imp.Requested,
+				About:      imp.About,
+				ApprovedBy: userRef(imp.ApprovedBy),
+			}
+			item.Name, item.Username = names(item.PeerRef)
+			items = append(items, item)
+		}
+		if len(items) >= res.Count {
+			break
+		}
+
+		last := res.Importers[len(res.Importers)-1]
+		offset, err := b.Peers.ResolveUserID(ctx, last.UserID)
+		if err != nil {
+			return nil, err
+		}
+		req.OffsetDate, req.OffsetUser = last.Date, offset.InputUser()
+	}
+	return items, nil
+}
+
+func invitePreview(ctx context.Context, pm *peers.Manager, invite tg.ChatInviteClass) (types.InvitePreview, error) {
+	switch v := invite.(type) {
+	case *tg.ChatInvite:
+		kind := "group"
+		if v.Broadcast {
+			kind = "channel"
+		}
+		return types.InvitePreview{
+			Title:         v.Title,
+			Kind:          kind,
+			About:         v.About,
+			Members:       v.ParticipantsCount,
+			RequestNeeded: v.RequestNeeded,
+		}, nil
+	case *tg.ChatInviteAlready:
+		return chatPreview(ctx, pm, v.Chat, true)
+	case *tg.ChatInvitePeek:
+		return chatPreview(ctx, pm, v.Chat, false)
+	default:
+		return types.InvitePreview{}, fmt.Errorf("unexpected invite %T", invite)
+	}
+}
+
+func chatPreview(ctx context.Context, pm *peers.Manager, chat tg.ChatClass, joined bool) (types.InvitePreview, error) {
+	peer, err := invitePeer(ctx, pm, chat)
+	if err != nil {
+		return types.InvitePreview{}, err
+	}
+	preview := types.InvitePreview{
+		PeerRef: peerRefFromID(peer.TDLibPeerID()),
+		Title:   peer.VisibleName(),
+		Kind:    peerKind(peer),
+		Joined:  joined,
+	}
+	switch p := peer.(type) {
+	case peers.Chat:
+		preview.Members = p.Raw().ParticipantsCount
+	case peers.Channel:
+		preview.Members, _ = p.Raw().GetParticipantsCount()
+	}
+	return preview, nil
+}
+
+func inviteItem(inv *tg.ChatInviteExported) types.InviteItem {
+	return types.InviteItem{
+		Link:          inv.Link,
+		Title:         inv.Title,
+		Creator:       userRef(inv.AdminID),
+		CreatedAt:     unixTime(inv.Date),
+		ExpiresAt:     unixTime(inv.ExpireDate),
+		UsageLimit:    inv.UsageLimit,
+		Usage:         inv.Usage,
+		Requested:     inv.Requested,
+		RequestNeeded: inv.RequestNeeded,
+		Permanent:     inv.Permanent,
+		Revoked:       inv.Revoked,
+	}
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/ghillb/tmgc/internal/output"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func TestInviteHash(t *testing.T) {
+	cases := map[string]string{
+		"https://t.me/+AbCdEf123":    "AbCdEf123",
+		"t.me/+AbCdEf123":            "AbCdEf123",
+		"t.me/joinchat/AbCdEf123":    "AbCdEf123",
+		"tg://join?invite=AbCdEf123": "AbCdEf123",
+		" https://t.me/+AbCdEf123/ ": "AbCdEf123",
+	}
+	for in, want := range cases {
+		got, ok := inviteHash(in)
+		if !ok || got != want {
+			t.Fatalf("inviteHash(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"@telegram", "t.me/telegram", "ch123", "+15551234567"} {
+		if _, ok := inviteHash(in); ok {
+			t.Fatalf("expected %q not to be an invite link", in)
+		}
+	}
+}
+
+func TestInviteItem(t *testing.T) {
+	item := inviteItem(&tg.ChatInviteExported{
+		Link:          "https://t.me/+abc",
+		AdminID:       42,
+		Date:          1700000000,
+		RequestNeeded: true,
+		Requested:     3,
+	})
+	if item.Creator != "u42" || item.Requested != 3 || !item.RequestNeeded {
+		t.Fatalf("unexpected item: %+v", item)
+	}
+	if !item.CreatedAt.Equal(time.Unix(1700000000, 0)) || !item.ExpiresAt.IsZero() {
+		t.Fatalf("unexpected dates: %+v", item)
+	}
+}
+
+func TestJoinRequestWatcherEmitsOnce(t *testing.T) {
+	var out bytes.Buffer
+	w := &joinRequestWatcher{
+		rt:   &Runtime{Printer: output.NewPrinter(&out, &out, output.ModeJSON, true)},
+		seen: make(map[string]bool),
+	}
+	for _, ref := range []string{"u1", "u2", "u1"} {
+		if err := w.emit(types.InviteImporterItem{PeerRef: ref, Requested: true}); err != nil {
+			t.Fatalf("emit: %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"u1"`) || !strings.Contains(lines[1], `"u2"`) {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestInviteExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 5, 9, 30, 0, 0, time.UTC)
+	if _, err := parseFutureUntil("24h", now, 0, "expiry time"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, in := range []string{"2020-01-01T00:00:00Z", "2026-01-05T09:30:00Z"} {
+		if _, err := parseFutureUntil(in, now, 0, "expiry time"); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+// maxCallbackData is Telegram's limit for callback button data, in bytes.
+const maxCallbackData = 64
+
+// markupSpec holds the keyboard flags of message send. Each row string holds
+// the buttons of one row separated by "|".
+type markupSpec struct {
+	Buttons        []string
+	Keyboard       []string
+	KeyboardOnce   bool
+	KeyboardResize bool
+	Placeholder    string
+	RemoveKeyboard bool
+	ForceReply     bool
+}
+
+// buildMarkup returns the reply markup for the flags, or nil if none is set.
+func buildMarkup(s markupSpec) (tg.ReplyMarkupClass, error) {
+	kinds := 0
+	for _, set := range []bool{len(s.Buttons) > 0, len(s.Keyboard) > 0, s.RemoveKeyboard, s.ForceReply} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds > 1 {
+		return nil, fmt.Errorf("use only one of --button, --keyboard, --remove-keyboard and --force-reply")
+	}
+	if (s.KeyboardOnce || s.KeyboardResize) && len(s.Keyboard) == 0 {
+		return nil, fmt.Errorf("--keyboard-once and --keyboard-resize require --keyboard")
+	}
+	if s.Placeholder != "" && len(s.Keyboard) == 0 && !s.ForceReply {
+		return nil, fmt.Errorf("--placeholder requires --keyboard or --force-reply")
+	}
+
+	switch {
+	case len(s.Buttons) > 0:
+		rows, err := buttonRows(s.Buttons, parseInlineButton)
+		if err != nil {
+			return nil, err
+		}
+		return &tg.ReplyInlineMarkup{Rows: rows}, nil
+	case len(s.Keyboard) > 0:
+		rows, err := buttonRows(s.Keyboard, func(text string) (tg.KeyboardButtonClass, error) {
+			return &tg.KeyboardButton{Text: text}, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		markup := &tg.ReplyKeyboardMarkup{
+			Rows:      rows,
+			Resize:    s.KeyboardResize,
+			SingleUse: s.KeyboardOnce,
+		}
+		if s.Placeholder != "" {
+			markup.SetPlaceholder(s.Placeholder)
+		}
+		return markup, nil
+	case s.RemoveKeyboard:
+		return &tg.ReplyKeyboardHide{}, nil
+	case s.ForceReply:
+		markup := &tg.ReplyKeyboardForceReply{}
+		if s.Placeholder != "" {
+			markup.SetPlaceholder(s.Placeholder)
+		}
+		return markup, nil
+	}
+	return nil, nil
+}
+
+func buttonRows(specs []string, parse func(string) (tg.KeyboardButtonClass, error)) ([]tg.KeyboardButtonRow, error) {
+	rows := make([]tg.KeyboardButtonRow, 0, len(specs))
+	for _, spec := range specs {
+		var row tg.KeyboardButtonRow
+		for _, part := range strings.Split(spec, "|") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				return nil, fmt.Errorf("empty button in row %q", spec)
+			}
+			button, err := parse(part)
+			if err != nil {
+				return nil, err
+			}
+			row.Buttons = append(row.Buttons, button)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseInlineButton reads "Text=url:<url>", "Text=cb:<data>",
+// "Text=switch:<query>" or "Text=copy:<text>".
+func parseInlineButton(spec string) (tg.KeyboardButtonClass, error) {
+	text, action, ok := strings.Cut(spec, "=")
+	text = strings.TrimSpace(text)
+	kind, value, hasKind := strings.Cut(action, ":")
+	if !ok || text == "" || !hasKind {
+		return nil, fmt.Errorf("invalid button %q (use Text=url:..., Text=cb:..., Text=switch:... or Text=copy:...)", spec)
+	}
+
+	switch kind {
+	case "url":
+		if !strings.Contains(value, "://") && !strings.HasPrefix(value, "tg:") {
+			return nil, fmt.Errorf("button %q: url must include a scheme", text)
+		}
+		return &tg.KeyboardButtonURL{Text: text, URL: value}, nil
+	case "cb":
+		if value == "" || len(value) > maxCallbackData {
+			return nil, fmt.Errorf("button %q: callback data must be 1-%d bytes", text, maxCallbackData)
+		}
+		return &tg.KeyboardButtonCallback{Text: text, Data: []byte(value)}, nil
+	case "switch":
+		return &tg.KeyboardButtonSwitchInline{Text: text, Query: value}, nil
+	case "copy":
+		return &tg.KeyboardButtonCopy{Text: text, CopyText: value}, nil
+	default:
+		return nil, fmt.Errorf("button %q: unknown action %q (use url, cb, switch or copy)", text, kind)
+	}
+}
+
+func newMessageClickCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "click <peer> <id> <button>",
+		Short: "Press an inline button of a bot message",
+		Long: `Press an inline button of a bot message. <button> is the button text
+(case-insensitive) or its number, counting left to right, top to bottom from 1.
+
+Callback buttons are sent to the bot and its answer is printed. URL buttons
+are not opened; their URL is printed instead.`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			msgID, err := parseMessageID(args[1])
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+				api := b.Client.API()
+
+				msg, err := getMessage(ctx, api, peer, msgID)
+				if err != nil {
+					return err
+				}
+				button, err := findInlineButton(msg, args[2])
+				if err != nil {
+					return err
+				}
+
+				result := types.CallbackAnswer{Button: button.GetText()}
+				switch btn := button.(type) {
+				case *tg.KeyboardButtonURL:
+					result.URL = btn.URL
+				case *tg.KeyboardButtonCallback, *tg.KeyboardButtonGame:
+					req := &tg.MessagesGetBotCallbackAnswerRequest{Peer: peer.InputPeer(), MsgID: msgID}
+					if cb, ok := btn.(*tg.KeyboardButtonCallback); ok {
+						if cb.RequiresPassword {
+							return fmt.Errorf("button %q requires the 2FA password", cb.Text)
+						}
+						req.SetData(cb.Data)
+					} else {
+						req.SetGame(true)
+					}
+					answer, err := api.MessagesGetBotCallbackAnswer(ctx, req)
+					if err != nil {
+						return err
+					}
+					result.Message = answer.Message
+					result.Alert = answer.Alert
+					result.URL = answer.URL
+				default:
+					return fmt.Errorf("button %q (%T) cannot be clicked from the CLI", button.GetText(), button)
+				}
+				return rt.Printer.Render(result)
+			})
+		},
+	}
+	return cmd
+}
+
+// findInlineButton looks up a button by number or text.
+func findInlineButton(msg *tg.Message, ref string) (tg.KeyboardButtonClass, error) {
+	buttons := inlineButtons(msg)
+	if len(buttons) == 0 {
+		return nil, fmt.Errorf("message %d has no inline buttons", msg.ID)
+	}
+	if n, err := strconv.Atoi(ref); err == nil && n >= 1 && n <= len(buttons) {
+		return buttons[n-1], nil
+	}
+	for _, b := range buttons {
+		if strings.EqualFold(strings.TrimSpace(b.GetText()), strings.TrimSpace(ref)) {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("no button %q (use 1-%d or the button text)", ref, len(buttons))
+}
+
+// inlineButtons returns the inline buttons of a message in reading order.
+func inlineButtons(msg *tg.Message) []tg.KeyboardButtonClass {
+	markup, ok := msg.ReplyMarkup.(*tg.ReplyInlineMarkup)
+	if !ok {
+		return nil
+	}
+	var buttons []tg.KeyboardButtonClass
+	for _, row := range markup.Rows {
+		buttons = append(buttons, row.Buttons...)
+	}
+	return buttons
+}
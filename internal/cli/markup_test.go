@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestBuildMarkup(t *testing.T) {
+	t.Run("none", func(t *testing.T) {
+		markup, err := buildMarkup(markupSpec{})
+		if err != nil || markup != nil {
+			t.Fatalf("expected no markup, got %v, %v", markup, err)
+		}
+	})
+
+	t.Run("inline", func(t *testing.T) {
+		markup, err := buildMarkup(markupSpec{Buttons: []string{"Docs=url:https://example.com", "Yes=cb:yes | No=cb:no"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		inline, ok := markup.(*tg.ReplyInlineMarkup)
+		if !ok {
+			t.Fatalf("expected inline markup, got %T", markup)
+		}
+		if len(inline.Rows) != 2 || len(inline.Rows[0].Buttons) != 1 || len(inline.Rows[1].Buttons) != 2 {
+			t.Fatalf("unexpected rows %+v", inline.Rows)
+		}
+		no, ok := inline.Rows[1].Buttons[1].(*tg.KeyboardButtonCallback)
+		if !ok || no.Text != "No" || string(no.Data) != "no" {
+			t.Fatalf("unexpected button %+v", inline.Rows[1].Buttons[1])
+		}
+	})
+
+	t.Run("keyboard", func(t *testing.T) {
+		markup, err := buildMarkup(markupSpec{Keyboard: []string{"A|B", "C"}, KeyboardOnce: true, Placeholder: "Pick one"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		kb, ok := markup.(*tg.ReplyKeyboardMarkup)
+		if !ok {
+			t.Fatalf("expected reply keyboard, got %T", markup)
+		}
+		if len(kb.Rows) != 2 || !kb.SingleUse || kb.Resize || kb.Placeholder != "Pick one" {
+			t.Fatalf("unexpected keyboard %+v", kb)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		markup, err := buildMarkup(markupSpec{RemoveKeyboard: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := markup.(*tg.ReplyKeyboardHide); !ok {
+			t.Fatalf("expected keyboard hide, got %T", markup)
+		}
+	})
+
+	errCases := map[string]markupSpec{
+		"two kinds":         {Buttons: []string{"A=cb:a"}, Keyboard: []string{"B"}},
+		"once alone":        {KeyboardOnce: true},
+		"placeholder":       {Buttons: []string{"A=cb:a"}, Placeholder: "x"},
+		"empty button":      {Keyboard: []string{"A||B"}},
+		"invalid inline":    {Buttons: []string{"A"}},
+		"remove with force": {RemoveKeyboard: true, ForceReply: true},
+	}
+	for name, spec := range errCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := buildMarkup(spec); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+}
+
+func TestParseInlineButton(t *testing.T) {
+	button, err := parseInlineButton("Search=switch:cats")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sw, ok := button.(*tg.KeyboardButtonSwitchInline); !ok || sw.Query != "cats" {
+		t.Fatalf("unexpected button %+v", button)
+	}
+
+	button, err = parseInlineButton("Open=url:https://example.com/?a=b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u, ok := button.(*tg.KeyboardButtonURL); !ok || u.URL != "https://example.com/?a=b" {
+		t.Fatalf("unexpected button %+v", button)
+	}
+
+	for _, spec := range []string{
+		"=cb:x",
+		"A=cb:",
+		"A=cb:" + strings.Repeat("x", maxCallbackData+1),
+		"A=url:example.com",
+		"A=tel:123",
+	} {
+		if _, err := parseInlineButton(spec); err == nil {
+			t.Fatalf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestFindInlineButton(t *testing.T) {
+	msg := &tg.Message{ID: 7, ReplyMarkup: &tg.ReplyInlineMarkup{Rows: []tg.KeyboardButtonRow{
+		{Buttons: []tg.KeyboardButtonClass{
+			&tg.KeyboardButtonCallback{Text: "Approve", Data: []byte("a")},
+			&tg.KeyboardButtonCallback{Text: "Reject", Data: []byte("r")},
+		}},
+		{Buttons: []tg.KeyboardButtonClass{&tg.KeyboardButtonURL{Text: "Docs", URL: "https://example.com"}}},
+	}}}
+
+	for ref, want := range map[string]string{"1": "Approve", "reject": "Reject", "3": "Docs"} {
+		button, err := findInlineButton(msg, ref)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", ref, err)
+		}
+		if button.GetText() != want {
+			t.Fatalf("%s: expected %s, got %s", ref, want, button.GetText())
+		}
+	}
+	if _, err := findInlineButton(msg, "4"); err == nil {
+		t.Fatalf("expected error for missing button")
+	}
+	if _, err := findInlineButton(&tg.Message{ID: 8}, "1"); err == nil {
+		t.Fatalf("expected error for message without buttons")
+	}
+}
@@ -0,0 +1,330 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+// liveForever is the live period Telegram treats as "until stopped".
+const liveForever = math.MaxInt32
+
+// sendMediaSpec holds the non-file media flags of message send.
+type sendMediaSpec struct {
+	Location   string
+	LivePeriod string
+	Venue      string
+	Address    string
+	Contact    string
+	Dice       string
+	// Text is the trailing message text; it is the name of a contact and
+	// not allowed for other media.
+	Text string
+}
+
+func (s sendMediaSpec) empty() bool {
+	return s.Location == "" && s.Venue == "" && s.Contact == "" && s.Dice == ""
+}
+
+// buildSendMedia turns the media flags into an input media.
+func buildSendMedia(s sendMediaSpec) (tg.InputMediaClass, error) {
+	kinds := 0
+	for _, set := range []bool{s.Location != "" && s.Venue == "", s.Venue != "", s.Contact != "", s.Dice != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds > 1 {
+		return nil, fmt.Errorf("use only one of --location, --venue, --contact and --dice")
+	}
+	if s.LivePeriod != "" && (s.Location == "" || s.Venue != "") {
+		return nil, fmt.Errorf("--live-period requires --location")
+	}
+	if s.Address != "" && s.Venue == "" {
+		return nil, fmt.Errorf("--address requires --venue")
+	}
+	text := strings.TrimSpace(s.Text)
+	if s.Contact == "" && text != "" {
+		return nil, fmt.Errorf("message text cannot be combined with this media")
+	}
+
+	switch {
+	case s.Contact != "":
+		if text == "" {
+			return nil, fmt.Errorf("--contact needs a name as message text")
+		}
+		first, last, _ := strings.Cut(text, " ")
+		return &tg.InputMediaContact{
+			PhoneNumber: strings.TrimSpace(s.Contact),
+			FirstName:   first,
+			LastName:    strings.TrimSpace(last),
+		}, nil
+	case s.Dice != "":
+		return &tg.InputMediaDice{Emoticon: s.Dice}, nil
+	case s.Venue != "":
+		if s.Location == "" {
+			return nil, fmt.Errorf("--venue requires --location")
+		}
+		fix, err := parseGeoFix(s.Location)
+		if err != nil {
+			return nil, err
+		}
+		return &tg.InputMediaVenue{
+			GeoPoint: fix.point(),
+			Title:    s.Venue,
+			Address:  s.Address,
+		}, nil
+	default:
+		fix, err := parseGeoFix(s.Location)
+		if err != nil {
+			return nil, err
+		}
+		if s.LivePeriod == "" {
+			return &tg.InputMediaGeoPoint{GeoPoint: fix.point()}, nil
+		}
+		period, err := parseLivePeriod(s.LivePeriod)
+		if err != nil {
+			return nil, err
+		}
+		live := &tg.InputMediaGeoLive{GeoPoint: fix.point()}
+		live.SetPeriod(period)
+		fix.applyLive(live)
+		return live, nil
+	}
+}
+
+func newMessageLiveLocationCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "live-location",
+		Short: "Update or stop a live location",
+	}
+
+	cmd.AddCommand(newLiveLocationUpdateCmd())
+	cmd.AddCommand(newLiveLocationStopCmd())
+	return cmd
+}
+
+func newLiveLocationUpdateCmd() *cobra.Command {
+	var (
+		location string
+		heading  int
+		accuracy int
+		stdin    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update <peer> <id>",
+		Short: "Move a live location",
+		Long: `Move a live location sent with message send --location --live-period.
+
+With --stdin, each input line "lat,lon[,heading[,accuracy]]" is one update,
+sent on the same connection until input ends. Blank lines are skipped.`,
+		Args:        cobra.ExactArgs(2),
+		Annotations: map[string]string{longRunningAnnotation: "stdin"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			msgID, err := parseMessageID(args[1])
+			if err != nil {
+				return err
+			}
+			if (location != "") == stdin {
+				return fmt.Errorf("use --location or --stdin")
+			}
+			var fix geoFix
+			if location != "" {
+				if fix, err = parseGeoFix(location); err != nil {
+					return err
+				}
+				if cmd.Flags().Changed("heading") {
+					fix.Heading = heading
+				}
+				if cmd.Flags().Changed("accuracy") {
+					fix.Accuracy = accuracy
+				}
+				if err := fix.validate(); err != nil {
+					return err
+				}
+			}
+
+			timeout := rt.Timeout
+			if stdin {
+				// Input may arrive slowly; the connection lives until it ends.
+				timeout = 0
+			}
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+				update := func(fix geoFix) error {
+					live := &tg.InputMediaGeoLive{GeoPoint: fix.point()}
+					fix.applyLive(live)
+					_, err := b.Client.API().MessagesEditMessage(ctx, &tg.MessagesEditMessageRequest{
+						Peer:  peer.InputPeer(),
+						ID:    msgID,
+						Media: live,
+					})
+					if err != nil && !tgerr.Is(err, "MESSAGE_NOT_MODIFIED") {
+						return err
+					}
+					return nil
+				}
+
+				if !stdin {
+					if err := update(fix); err != nil {
+						return err
+					}
+					return rt.Printer.Render(types.SendResult{OK: true, MessageID: msgID})
+				}
+
+				scanner := bufio.NewScanner(cmd.InOrStdin())
+				for line := 1; scanner.Scan(); line++ {
+					text := strings.TrimSpace(scanner.Text())
+					if text == "" {
+						continue
+					}
+					fix, err := parseGeoFix(text)
+					if err != nil {
+						return fmt.Errorf("line %d: %w", line, err)
+					}
+					if err := update(fix); err != nil {
+						return fmt.Errorf("line %d: %w", line, err)
+					}
+					if err := renderStream(rt, types.SendResult{OK: true, MessageID: msgID}); err != nil {
+						return err
+					}
+				}
+				return scanner.Err()
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&location, "location", "", "new position as lat,lon")
+	cmd.Flags().IntVar(&heading, "heading", 0, "direction of movement in degrees (1-360)")
+	cmd.Flags().IntVar(&accuracy, "accuracy", 0, "accuracy radius in meters")
+	cmd.Flags().BoolVar(&stdin, "stdin", false, "read one position per line from stdin")
+	return cmd
+}
+
+func newLiveLocationStopCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stop <peer> <id>",
+		Short: "Stop sharing a live location",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			msgID, err := parseMessageID(args[1])
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				if _, err := b.Client.API().MessagesEditMessage(ctx, &tg.MessagesEditMessageRequest{
+					Peer:  peer.InputPeer(),
+					ID:    msgID,
+					Media: &tg.InputMediaGeoLive{Stopped: true, GeoPoint: &tg.InputGeoPointEmpty{}},
+				}); err != nil && !tgerr.Is(err, "MESSAGE_NOT_MODIFIED") {
+					return err
+				}
+				return rt.Printer.Render(types.SendResult{OK: true, MessageID: msgID})
+			})
+		},
+	}
+	return cmd
+}
+
+// geoFix is one position, optionally with heading and accuracy.
+type geoFix struct {
+	Lat, Lon float64
+	Heading  int
+	Accuracy int
+}
+
+// parseGeoFix reads "lat,lon[,heading[,accuracy]]".
+func parseGeoFix(value string) (geoFix, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) < 2 || len(parts) > 4 {
+		return geoFix{}, fmt.Errorf("invalid location %q (use lat,lon)", value)
+	}
+	nums := make([]float64, len(parts))
+	for i, p := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return geoFix{}, fmt.Errorf("invalid location %q (use lat,lon)", value)
+		}
+		nums[i] = n
+	}
+	fix := geoFix{Lat: nums[0], Lon: nums[1]}
+	if len(nums) > 2 {
+		fix.Heading = int(nums[2])
+	}
+	if len(nums) > 3 {
+		fix.Accuracy = int(nums[3])
+	}
+	return fix, fix.validate()
+}
+
+func (f geoFix) validate() error {
+	switch {
+	case f.Lat < -90 || f.Lat > 90:
+		return fmt.Errorf("latitude %v out of range (-90 to 90)", f.Lat)
+	case f.Lon < -180 || f.Lon > 180:
+		return fmt.Errorf("longitude %v out of range (-180 to 180)", f.Lon)
+	case f.Heading < 0 || f.Heading > 360:
+		return fmt.Errorf("heading %d out of range (1 to 360)", f.Heading)
+	case f.Accuracy < 0:
+		return fmt.Errorf("accuracy cannot be negative")
+	}
+	return nil
+}
+
+func (f geoFix) point() *tg.InputGeoPoint {
+	p := &tg.InputGeoPoint{Lat: f.Lat, Long: f.Lon}
+	if f.Accuracy > 0 {
+		p.SetAccuracyRadius(f.Accuracy)
+	}
+	return p
+}
+
+func (f geoFix) applyLive(live *tg.InputMediaGeoLive) {
+	if f.Heading > 0 {
+		live.SetHeading(f.Heading)
+	}
+}
+
+// parseLivePeriod reads a live location duration between 1m and 24h, or
+// "forever".
+func parseLivePeriod(value string) (int, error) {
+	if value == "forever" {
+		return liveForever, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < time.Minute || d > 24*time.Hour {
+		return 0, fmt.Errorf("invalid --live-period %q (use 1m to 24h, or forever)", value)
+	}
+	return int(d / time.Second), nil
+}
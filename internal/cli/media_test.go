@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestBuildSendMedia(t *testing.T) {
+	t.Run("location", func(t *testing.T) {
+		media, err := buildSendMedia(sendMediaSpec{Location: "52.52, 13.405"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		geo, ok := media.(*tg.InputMediaGeoPoint)
+		if !ok {
+			t.Fatalf("expected geo point, got %T", media)
+		}
+		point := geo.GeoPoint.(*tg.InputGeoPoint)
+		if point.Lat != 52.52 || point.Long != 13.405 {
+			t.Fatalf("unexpected point %+v", point)
+		}
+	})
+
+	t.Run("live", func(t *testing.T) {
+		media, err := buildSendMedia(sendMediaSpec{Location: "1,2,90", LivePeriod: "15m"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		live, ok := media.(*tg.InputMediaGeoLive)
+		if !ok {
+			t.Fatalf("expected live location, got %T", media)
+		}
+		if live.Period != 900 || live.Heading != 90 {
+			t.Fatalf("unexpected live location %+v", live)
+		}
+	})
+
+	t.Run("venue", func(t *testing.T) {
+		media, err := buildSendMedia(sendMediaSpec{Location: "1,2", Venue: "Office", Address: "Main St 1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		venue, ok := media.(*tg.InputMediaVenue)
+		if !ok || venue.Title != "Office" || venue.Address != "Main St 1" {
+			t.Fatalf("unexpected venue %#v", media)
+		}
+	})
+
+	t.Run("contact", func(t *testing.T) {
+		media, err := buildSendMedia(sendMediaSpec{Contact: "+491234", Text: "Jane van Doe"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		contact, ok := media.(*tg.InputMediaContact)
+		if !ok || contact.PhoneNumber != "+491234" || contact.FirstName != "Jane" || contact.LastName != "van Doe" {
+			t.Fatalf("unexpected contact %#v", media)
+		}
+	})
+
+	t.Run("dice", func(t *testing.T) {
+		media, err := buildSendMedia(sendMediaSpec{Dice: "🎯"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dice, ok := media.(*tg.InputMediaDice); !ok || dice.Emoticon != "🎯" {
+			t.Fatalf("unexpected dice %#v", media)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, spec := range map[string]sendMediaSpec{
+			"two kinds":        {Location: "1,2", Dice: "🎲"},
+			"venue no loc":     {Venue: "Office"},
+			"address no venue": {Location: "1,2", Address: "x"},
+			"live no loc":      {LivePeriod: "15m"},
+			"live venue":       {Location: "1,2", Venue: "Office", LivePeriod: "15m"},
+			"live too long":    {Location: "1,2", LivePeriod: "48h"},
+			"contact no name":  {Contact: "+491234"},
+			"text with dice":   {Dice: "🎲", Text: "hi"},
+			"bad location":     {Location: "north"},
+		} {
+			if _, err := buildSendMedia(spec); err == nil {
+				t.Fatalf("%s: expected error", name)
+			}
+		}
+	})
+}
+
+func TestParseGeoFix(t *testing.T) {
+	fix, err := parseGeoFix("-33.86,151.2,270,15")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fix != (geoFix{Lat: -33.86, Lon: 151.2, Heading: 270, Accuracy: 15}) {
+		t.Fatalf("unexpected fix %+v", fix)
+	}
+	for _, bad := range []string{"1", "1,2,3,4,5", "91,0", "0,181", "0,0,361", "0,0,0,-1", "a,b"} {
+		if _, err := parseGeoFix(bad); err == nil {
+			t.Fatalf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestParseLivePeriod(t *testing.T) {
+	if got, err := parseLivePeriod("forever"); err != nil || got != liveForever {
+		t.Fatalf("forever: got %d, %v", got, err)
+	}
+	if got, err := parseLivePeriod("1h"); err != nil || got != 3600 {
+		t.Fatalf("1h: got %d, %v", got, err)
+	}
+	for _, bad := range []string{"30s", "25h", "soon"} {
+		if _, err := parseLivePeriod(bad); err == nil {
+			t.Fatalf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestMessageSendDiceFlag(t *testing.T) {
+	cmd := newMessageSendCmd()
+	if err := cmd.ParseFlags([]string{"u1", "--dice", "🎯"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	args := cmd.Flags().Args()
+	if err := cmd.ValidateArgs(args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(args) != 1 || args[0] != "u1" {
+		t.Fatalf("expected only the peer as argument, got %v", args)
+	}
+	dice, _ := cmd.Flags().GetString("dice")
+	if dice != "🎯" {
+		t.Fatalf("expected dice 🎯, got %q", dice)
+	}
+}
+
+func TestMessageSendCaptionWithMedia(t *testing.T) {
+	cmd := newMessageSendCmd()
+	if err := cmd.ParseFlags([]string{"u1", "--dice", "🎲", "--caption", "good luck"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cmd.ValidateArgs(cmd.Flags().Args()); err == nil {
+		t.Fatalf("expected --caption with --dice to be rejected")
+	}
+}
@@ -0,0 +1,492 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/constant"
+	"github.com/gotd/td/telegram/peers"
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func newMemberCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "member",
+		Short: "Group and channel membership",
+	}
+
+	cmd.AddCommand(newMemberListCmd())
+	cmd.AddCommand(newMemberAddCmd())
+	cmd.AddCommand(newMemberKickCmd())
+	cmd.AddCommand(newMemberBanCmd())
+	cmd.AddCommand(newMemberUnbanCmd())
+	cmd.AddCommand(newMemberPromoteCmd())
+	cmd.AddCommand(newMemberDemoteCmd())
+
+	return cmd
+}
+
+func newMemberListCmd() *cobra.Command {
+	var (
+		filter string
+		limit  int
+		all    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list <peer>",
+		Short: "List members of a group or channel",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			mf, err := parseMemberFilter(filter)
+			if err != nil {
+				return err
+			}
+			if all {
+				limit = 0
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				var items []types.MemberItem
+				switch p := peer.(type) {
+				case peers.Channel:
+					items, err = loadChannelMembers(ctx, b, p, mf, limit)
+				case peers.Chat:
+					items, err = loadChatMembers(ctx, b, p, mf, limit)
+				default:
+					return fmt.Errorf("%s is not a group or channel", args[0])
+				}
+				if err != nil {
+					return err
+				}
+				return rt.Printer.Render(items)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&filter, "filter", "", "recent, admins, bots, banned, kicked, search:<q> or contacts:<q>")
+	cmd.Flags().IntVar(&limit, "limit", 200, "limit number of members")
+	cmd.Flags().BoolVar(&all, "all", false, "list every member (ignores --limit)")
+	return cmd
+}
+
+func newMemberAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <peer> <user...>",
+		Short: "Add users to a group or channel",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+				users, err := resolveUsers(ctx, b.Peers, args[1:])
+				if err != nil {
+					return err
+				}
+
+				api := b.Client.API()
+				switch p := peer.(type) {
+				case peers.Channel:
+					res, err := api.ChannelsInviteToChannel(ctx, &tg.ChannelsInviteToChannelRequest{
+						Channel: p.InputChannel(),
+						Users:   users,
+					})
+					if err != nil {
+						return err
+					}
+					warnMissingInvitees(rt, res.MissingInvitees)
+				case peers.Chat:
+					for _, user := range users {
+						res, err := api.MessagesAddChatUser(ctx, &tg.MessagesAddChatUserRequest{
+							ChatID:   p.ID(),
+							UserID:   user,
+							FwdLimit: 100,
+						})
+						if err != nil {
+							return err
+						}
+						warnMissingInvitees(rt, res.MissingInvitees)
+					}
+				default:
+					return fmt.Errorf("%s is not a group or channel", args[0])
+				}
+				return rt.Printer.Render(types.SendResult{OK: true})
+			})
+		},
+	}
+	return cmd
+}
+
+func newMemberKickCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kick <peer> <user>",
+		Short: "Remove a member (they can rejoin)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMemberAction(cmd, args, func(ctx context.Context, api *tg.Client, chat peers.Peer, user peers.User) error {
+				switch p := chat.(type) {
+				case peers.Channel:
+					// Ban, then lift the ban so the user is removed but not blocked.
+					if err := editBanned(ctx, api, p, user, tg.ChatBannedRights{ViewMessages: true}); err != nil {
+						return err
+					}
+					return editBanned(ctx, api, p, user, tg.ChatBannedRights{})
+				case peers.Chat:
+					_, err := api.MessagesDeleteChatUser(ctx, &tg.MessagesDeleteChatUserRequest{
+						ChatID: p.ID(),
+						UserID: user.InputUser(),
+					})
+					return err
+				default:
+					return fmt.Errorf("%s is not a group or channel", args[0])
+				}
+			})
+		},
+	}
+	return cmd
+}
+
+// minBanDuration is the shortest temporary ban; Telegram bans forever when
+// the end is sooner.
+const minBanDuration = 30 * time.Second
+
+func newMemberBanCmd() *cobra.Command {
+	var until string
+
+	cmd := &cobra.Command{
+		Use:   "ban <peer> <user>",
+		Short: "Ban a member from a supergroup or channel",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			untilTime, err := parseFutureUntil(until, time.Now(), minBanDuration, "ban end")
+			if err != nil {
+				return err
+			}
+			return runMemberAction(cmd, args, func(ctx context.Context, api *tg.Client, chat peers.Peer, user peers.User) error {
+				ch, ok := chat.(peers.Channel)
+				if !ok {
+					return fmt.Errorf("ban requires a supergroup or channel; use `member kick` for basic groups")
+				}
+				rights := tg.ChatBannedRights{ViewMessages: true}
+				if !untilTime.IsZero() {
+					rights.UntilDate = int(untilTime.Unix())
+				}
+				return editBanned(ctx, api, ch, user, rights)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&until, "until", "", "ban end (RFC3339 or duration like 24h, 7d); default forever")
+	return cmd
+}
+
+func newMemberUnbanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unban <peer> <user>",
+		Short: "Lift a ban or restriction",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMemberAction(cmd, args, func(ctx context.Context, api *tg.Client, chat peers.Peer, user peers.User) error {
+				ch, ok := chat.(peers.Channel)
+				if !ok {
+					return fmt.Errorf("unban requires a supergroup or channel")
+				}
+				return editBanned(ctx, api, ch, user, tg.ChatBannedRights{})
+			})
+		},
+	}
+	return cmd
+}
+
+// runMemberAction resolves <peer> <user> and runs fn on one connection.
+func runMemberAction(cmd *cobra.Command, args []string, fn func(ctx context.Context, api *tg.Client, chat peers.Peer, user peers.User) error) error {
+	rt, err := runtimeFrom(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+	return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+		chat, err := resolvePeer(ctx, b.Peers, args[0])
+		if err != nil {
+			return err
+		}
+		peer, err := resolvePeer(ctx, b.Peers, args[1])
+		if err != nil {
+			return err
+		}
+		user, ok := peer.(peers.User)
+		if !ok {
+			return fmt.Errorf("%s is not a user", args[1])
+		}
+
+		if err := fn(ctx, b.Client.API(), chat, user); err != nil {
+			return err
+		}
+		return rt.Printer.Render(types.SendResult{OK: true})
+	})
+}
+
+func editBanned(ctx context.Context, api *tg.Client, ch peers.Channel, user peers.User, rights tg.ChatBannedRights) error {
+	_, err := api.ChannelsEditBanned(ctx, &tg.ChannelsEditBannedRequest{
+		Channel:      ch.InputChannel(),
+		Participant:  user.InputPeer(),
+		BannedRights: rights,
+	})
+	return err
+}
+
+type memberFilter struct {
+	kind  string
+	query string
+}
+
+func parseMemberFilter(value string) (memberFilter, error) {
+	kind, query, _ := strings.Cut(value, ":")
+	switch kind {
+	case "", "recent", "admins", "bots", "banned", "kicked", "search", "contacts":
+		return memberFilter{kind: kind, query: query}, nil
+	default:
+		return memberFilter{}, fmt.Errorf("invalid --filter %q (use recent, admins, bots, banned, kicked, search:<q> or contacts:<q>)", value)
+	}
+}
+
+func (f memberFilter) channelFilter() tg.ChannelParticipantsFilterClass {
+	switch f.kind {
+	case "admins":
+		return &tg.ChannelParticipantsAdmins{}
+	case "bots":
+		return &tg.ChannelParticipantsBots{}
+	case "banned":
+		return &tg.ChannelParticipantsBanned{Q: f.query}
+	case "kicked":
+		return &tg.ChannelParticipantsKicked{Q: f.query}
+	case "search":
+		return &tg.ChannelParticipantsSearch{Q: f.query}
+	case "contacts":
+		return &tg.ChannelParticipantsContacts{Q: f.query}
+	default:
+		return &tg.ChannelParticipantsRecent{}
+	}
+}
+
+func loadChannelMembers(ctx context.Context, b *tgclient.Bundle, ch peers.Channel, f memberFilter, limit int) ([]types.MemberItem, error) {
+	req := &tg.ChannelsGetParticipantsRequest{
+		Channel: ch.InputChannel(),
+		Filter:  f.channelFilter(),
+	}
+
+	items := make([]types.MemberItem, 0)
+	for limit == 0 || len(items) < limit {
+		req.Limit = 200
+		if limit > 0 {
+			req.Limit = min(limit-len(items), 200)
+		}
+		res, err := b.Client.API().ChannelsGetParticipants(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		page, ok := res.(*tg.ChannelsChannelParticipants)
+		if !ok || len(page.Participants) == 0 {
+			break
+		}
+		if err := b.Peers.Apply(ctx, page.Users, page.Chats); err != nil {
+			return nil, err
+		}
+
+		names := memberNames(b.Peers, page.Users, page.Chats)
+		for _, p := range page.Participants {
+			item := channelMember(p)
+			item.Name, item.Username = names(item.PeerRef)
+			items = append(items, item)
+		}
+
+		req.Offset += len(page.Participants)
+		if req.Offset >= page.Count {
+			break
+		}
+	}
+	return items, nil
+}
+
+func loadChatMembers(ctx context.Context, b *tgclient.Bundle, chat peers.Chat, f memberFilter, limit int) ([]types.MemberItem, error) {
+	switch f.kind {
+	case "banned", "kicked", "contacts":
+		return nil, fmt.Errorf("--filter %s requires a supergroup or channel", f.kind)
+	}
+
+	res, err := b.Client.API().MessagesGetFullChat(ctx, chat.ID())
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Peers.Apply(ctx, res.Users, res.Chats); err != nil {
+		return nil, err
+	}
+	full, ok := res.FullChat.(*tg.ChatFull)
+	if !ok {
+		return nil, fmt.Errorf("unexpected full chat %T", res.FullChat)
+	}
+	participants, ok := full.Participants.(*tg.ChatParticipants)
+	if !ok {
+		return nil, fmt.Errorf("member list of %s is not available", chat.VisibleName())
+	}
+
+	bots := make(map[string]bool)
+	for _, u := range res.Users {
+		if user, ok := u.(*tg.User); ok && user.Bot {
+			var id constant.TDLibPeerID
+			id.User(user.ID)
+			bots[peerRefFromID(id)] = true
+		}
+	}
+
+	names := memberNames(b.Peers, res.Users, res.Chats)
+	items := make([]types.MemberItem, 0, len(participants.Participants))
+	for _, p := range participants.Participants {
+		item := chatMember(p)
+		item.Name, item.Username = names(item.PeerRef)
+
+		switch f.kind {
+		case "admins":
+			if item.Role != "creator" && item.Role != "admin" {
+				continue
+			}
+		case "bots":
+			if !bots[item.PeerRef] {
+				continue
+			}
+		case "search":
+			q := strings.ToLower(f.query)
+			if !strings.Contains(strings.ToLower(item.Name), q) && !strings.Contains(strings.ToLower(item.Username), q) {
+				continue
+			}
+		}
+		items = append(items, item)
+		if limit > 0 && len(items) >= limit {
+			break
+		}
+	}
+	return items, nil
+}
+
+// memberNames returns a lookup of display name and username by peer ref.
+func memberNames(m *peers.Manager, users []tg.UserClass, chats []tg.ChatClass) func(ref string) (string, string) {
+	userMap, chatMap, channelMap := buildPeerMaps(users, chats)
+	return func(ref string) (string, string) {
+		id, ok := parsePeerRef(ref)
+		if !ok {
+			return "", ""
+		}
+		var p tg.PeerClass
+		switch {
+		case id.IsUser():
+			p = &tg.PeerUser{UserID: id.ToPlain()}
+		case id.IsChat():
+			p = &tg.PeerChat{ChatID: id.ToPlain()}
+		case id.IsChannel():
+			p = &tg.PeerChannel{ChannelID: id.ToPlain()}
+		}
+		peer := peerFromDialog(m, p, userMap, chatMap, channelMap)
+		if peer == nil {
+			return "", ""
+		}
+		username, _ := peer.Username()
+		return peer.VisibleName(), username
+	}
+}
+
+func userRef(userID int64) string {
+	if userID == 0 {
+		return ""
+	}
+	var id constant.TDLibPeerID
+	id.User(userID)
+	return peerRefFromID(id)
+}
+
+func unixTime(v int) time.Time {
+	if v == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(v), 0)
+}
+
+func channelMember(p tg.ChannelParticipantClass) types.MemberItem {
+	switch v := p.(type) {
+	case *tg.ChannelParticipant:
+		return types.MemberItem{PeerRef: userRef(v.UserID), Role: "member", JoinedAt: unixTime(v.Date)}
+	case *tg.ChannelParticipantSelf:
+		return types.MemberItem{PeerRef: userRef(v.UserID), Role: "member", JoinedAt: unixTime(v.Date), Inviter: userRef(v.InviterID)}
+	case *tg.ChannelParticipantCreator:
+		return types.MemberItem{PeerRef: userRef(v.UserID), Role: "creator", Rank: v.Rank, Rights: formatAdminRights(v.AdminRights)}
+	case *tg.ChannelParticipantAdmin:
+		return types.MemberItem{
+			PeerRef:  userRef(v.UserID),
+			Role:     "admin",
+			Rank:     v.Rank,
+			JoinedAt: unixTime(v.Date),
+			Inviter:  userRef(v.InviterID),
+			Rights:   formatAdminRights(v.AdminRights),
+		}
+	case *tg.ChannelParticipantBanned:
+		item := types.MemberItem{
+			Role:         "restricted",
+			JoinedAt:     unixTime(v.Date),
+			Until:        unixTime(v.BannedRights.UntilDate),
+			Restrictions: formatBannedRights(v.BannedRights),
+		}
+		if v.BannedRights.ViewMessages {
+			item.Role = "banned"
+		}
+		if id, ok := peerIDFromPeerClass(v.Peer); ok {
+			item.PeerRef = peerRefFromID(id)
+		}
+		return item
+	case *tg.ChannelParticipantLeft:
+		item := types.MemberItem{Role: "left"}
+		if id, ok := peerIDFromPeerClass(v.Peer); ok {
+			item.PeerRef = peerRefFromID(id)
+		}
+		return item
+	default:
+		return types.MemberItem{}
+	}
+}
+
+func chatMember(p tg.ChatParticipantClass) types.MemberItem {
+	switch v := p.(type) {
+	case *tg.ChatParticipant:
+		return types.MemberItem{PeerRef: userRef(v.UserID), Role: "member", JoinedAt: unixTime(v.Date), Inviter: userRef(v.InviterID)}
+	case *tg.ChatParticipantCreator:
+		return types.MemberItem{PeerRef: userRef(v.UserID), Role: "creator"}
+	case *tg.ChatParticipantAdmin:
+		return types.MemberItem{PeerRef: userRef(v.UserID), Role: "admin", JoinedAt: unixTime(v.Date), Inviter: userRef(v.InviterID)}
+	default:
+		return types.MemberItem{}
+	}
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func TestParseUntil(t *testing.T) {
+	now := time.Date(2026, 1, 5, 9, 30, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"":                     {},
+		"90m":                  now.Add(90 * time.Minute),
+		"24h":                  now.Add(24 * time.Hour),
+		"7d":                   now.AddDate(0, 0, 7),
+		"2026-02-01T00:00:00Z": time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for in, want := range cases {
+		got, err := parseUntil(in, now)
+		if err != nil {
+			t.Fatalf("parseUntil(%q): %v", in, err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("parseUntil(%q) = %v, want %v", in, got, want)
+		}
+	}
+	for _, in := range []string{"tomorrow", "-1h", "0d"} {
+		if _, err := parseUntil(in, now); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
+
+func TestParseFutureUntil(t *testing.T) {
+	now := time.Date(2026, 1, 5, 9, 30, 0, 0, time.UTC)
+	if got, err := parseFutureUntil("", now, minBanDuration, "ban end"); err != nil || !got.IsZero() {
+		t.Fatalf("empty value = %v, %v; want no end time", got, err)
+	}
+	if _, err := parseFutureUntil("1m", now, minBanDuration, "ban end"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, in := range []string{"10s", "30s", "2020-01-01T00:00:00Z"} {
+		if _, err := parseFutureUntil(in, now, minBanDuration, "ban end"); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
+
+func TestParseMemberFilter(t *testing.T) {
+	f, err := parseMemberFilter("search:jane doe")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q, ok := f.channelFilter().(*tg.ChannelParticipantsSearch); !ok || q.Q != "jane doe" {
+		t.Fatalf("unexpected channel filter %#v", f.channelFilter())
+	}
+	if _, ok := (memberFilter{}).channelFilter().(*tg.ChannelParticipantsRecent); !ok {
+		t.Fatalf("expected recent filter by default")
+	}
+	if _, err := parseMemberFilter("owners"); err == nil {
+		t.Fatalf("expected error for unknown filter")
+	}
+}
+
+func TestChannelMember(t *testing.T) {
+	date := time.Date(2026, 1, 5, 9, 30, 0, 0, time.UTC)
+	cases := []struct {
+		in   tg.ChannelParticipantClass
+		want types.MemberItem
+	}{
+		{&tg.ChannelParticipant{UserID: 1, Date: int(date.Unix())},
+			types.MemberItem{PeerRef: "u1", Role: "member", JoinedAt: date}},
+		{&tg.ChannelParticipantAdmin{UserID: 2, InviterID: 1, Rank: "ops", Date: int(date.Unix())},
+			types.MemberItem{PeerRef: "u2", Role: "admin", Rank: "ops", JoinedAt: date, Inviter: "u1"}},
+		{&tg.ChannelParticipantBanned{Peer: &tg.PeerUser{UserID: 3}, BannedRights: tg.ChatBannedRights{ViewMessages: true, UntilDate: int(date.Unix())}},
+			types.MemberItem{PeerRef: "u3", Role: "banned", Until: date}},
+		{&tg.ChannelParticipantBanned{Peer: &tg.PeerChannel{ChannelID: 4}, BannedRights: tg.ChatBannedRights{SendMedia: true}},
+			types.MemberItem{PeerRef: "ch4", Role: "restricted"}},
+	}
+	for _, tc := range cases {
+		got := channelMember(tc.in)
+		if got.PeerRef != tc.want.PeerRef || got.Role != tc.want.Role || got.Rank != tc.want.Rank ||
+			got.Inviter != tc.want.Inviter || !got.JoinedAt.Equal(tc.want.JoinedAt) || !got.Until.Equal(tc.want.Until) {
+			t.Fatalf("channelMember(%T) = %+v, want %+v", tc.in, got, tc.want)
+		}
+	}
+
+	if got := chatMember(&tg.ChatParticipantCreator{UserID: 5}); got.PeerRef != "u5" || got.Role != "creator" {
+		t.Fatalf("chatMember() = %+v", got)
+	}
+}
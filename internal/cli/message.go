@@ -9,7 +9,6 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
@@ -28,23 +27,39 @@ func newMessageCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(newMessageSendCmd())
+	cmd.AddCommand(newMessageReactCmd())
+	cmd.AddCommand(newMessageReactionsCmd())
+	cmd.AddCommand(newMessagePinCmd())
+	cmd.AddCommand(newMessageUnpinCmd())
+	cmd.AddCommand(newMessageRepliesCmd())
+	cmd.AddCommand(newMessagePollCmd())
+	cmd.AddCommand(newMessageVoteCmd())
+	cmd.AddCommand(newMessagePollResultsCmd())
+	cmd.AddCommand(newMessagePollCloseCmd())
+	cmd.AddCommand(newMessageLiveLocationCmd())
+	cmd.AddCommand(newMessageClickCmd())
 
 	return cmd
 }
 
 func newMessageSendCmd() *cobra.Command {
 	var (
-		replyID  int
-		silent   bool
-		file     string
-		caption  string
-		voice    bool
-		schedule string
+		replyID   int
+		topicID   int
+		commentTo int
+		silent    bool
+		file      string
+		caption   string
+		voice     bool
+		schedule  string
+		tz        string
+		media     sendMediaSpec
+		markup    markupSpec
 	)
 
 	cmd := &cobra.Command{
 		Use:   "send <peer> [text]",
-		Short: "Send a text message or file",
+		Short: "Send a text message, file, location, venue, contact or dice",
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) < 1 {
 				return fmt.Errorf("peer is required")
@@ -52,7 +67,13 @@ func newMessageSendCmd() *cobra.Command {
 			if voice && file == "" {
 				return fmt.Errorf("--voice requires --file")
 			}
-			if file == "" && len(args) < 2 {
+			if topicID != 0 && commentTo != 0 {
+				return fmt.Errorf("use --topic or --comment-to, not both")
+			}
+			if caption != "" && !media.empty() {
+				return fmt.Errorf("--caption cannot be combined with --location, --venue, --contact or --dice")
+			}
+			if file == "" && media.empty() && len(args) < 2 {
 				return fmt.Errorf("message text cannot be empty")
 			}
 			return nil
@@ -69,22 +90,37 @@ func newMessageSendCmd() *cobra.Command {
 				return fmt.Errorf("use --caption or trailing text, not both")
 			}
 			text := strings.Join(textArgs, " ")
-			if file == "" && strings.TrimSpace(text) == "" {
+			var inputMedia tg.InputMediaClass
+			if !media.empty() {
+				if file != "" {
+					return fmt.Errorf("--file cannot be combined with --location, --venue, --contact or --dice")
+				}
+				media.Text = text
+				inputMedia, err = buildSendMedia(media)
+				if err != nil {
+					return err
+				}
+				text = ""
+			} else if media.LivePeriod != "" || media.Address != "" {
+				_, err := buildSendMedia(media)
+				return err
+			}
+			if file == "" && inputMedia == nil && strings.TrimSpace(text) == "" {
 				return fmt.Errorf("message text cannot be empty")
 			}
 			if file != "" && caption != "" {
 				text = caption
 			}
+			replyMarkup, err := buildMarkup(markup)
+			if err != nil {
+				return err
+			}
 			var scheduleDate int
 			if schedule != "" {
-				value, err := parseSchedule(schedule)
+				scheduleDate, err = scheduleTime(schedule, tz, time.Now())
 				if err != nil {
 					return err
 				}
-				if value <= int(time.Now().Unix()) {
-					return fmt.Errorf("schedule time must be in the future")
-				}
-				scheduleDate = value
 			}
 
 			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
@@ -94,100 +130,123 @@ func newMessageSendCmd() *cobra.Command {
 					return err
 				}
 
-				var updates tg.UpdatesClass
-				if file == "" {
-					req := &tg.MessagesSendMessageRequest{
-						Peer:     peer.InputPeer(),
-						Message:  text,
-						RandomID: rand.Int63(),
-						Silent:   silent,
-					}
-					if scheduleDate != 0 {
-						req.ScheduleDate = scheduleDate
-					}
-					if replyID != 0 {
-						req.ReplyTo = &tg.InputReplyToMessage{ReplyToMsgID: replyID}
-					}
-
-					updates, err = b.Client.API().MessagesSendMessage(ctx, req)
-					if err != nil {
-						return err
-					}
-				} else {
-					media, err := uploadMedia(ctx, b.Client.API(), file, uploadOptions{AsVoice: voice})
+				result := types.SendResult{OK: true}
+				threadID := topicID
+				if commentTo != 0 {
+					peer, threadID, err = discussionThread(ctx, b, peer, commentTo)
 					if err != nil {
 						return err
 					}
+					result.PeerRef = peerRefFromID(peer.TDLibPeerID())
+				}
 
-					req := &tg.MessagesSendMediaRequest{
-						Peer:     peer.InputPeer(),
-						Media:    media,
-						Message:  text,
-						RandomID: rand.Int63(),
-						Silent:   silent,
-					}
-					if scheduleDate != 0 {
-						req.ScheduleDate = scheduleDate
-					}
-					if replyID != 0 {
-						req.ReplyTo = &tg.InputReplyToMessage{ReplyToMsgID: replyID}
-					}
-					updates, err = b.Client.API().MessagesSendMedia(ctx, req)
-					if err != nil {
-						return err
-					}
+				updates, err := sendMessage(ctx, b.Client.API(), peer.InputPeer(), outgoingMessage{
+					Text:     text,
+					File:     file,
+					Media:    inputMedia,
+					Markup:   replyMarkup,
+					Voice:    voice,
+					Silent:   silent,
+					ReplyTo:  inputReplyTo(replyID, threadID),
+					Schedule: scheduleDate,
+				})
+				if err != nil {
+					return err
 				}
 
-				result := types.SendResult{OK: true}
 				if id, ok := extractSentMessageID(updates); ok {
 					result.MessageID = id
 				}
 				result.Updates = fmt.Sprintf("%T", updates)
 
-				switch rt.Printer.Mode {
-				case "json":
-					return rt.Printer.JSON(result)
-				case "plain":
-					line := fmt.Sprintf("%t\t%d", result.OK, result.MessageID)
-					rt.Printer.Plain([]string{line})
-				default:
-					rt.Printer.Table([][]string{{"OK", "MESSAGE_ID"}, {
-						fmt.Sprintf("%t", result.OK),
-						fmt.Sprintf("%d", result.MessageID),
-					}})
-				}
-				return nil
+				return rt.Printer.Render(result)
 			})
 		},
 	}
 
 	cmd.Flags().IntVar(&replyID, "reply", 0, "reply to message id")
+	cmd.Flags().IntVar(&topicID, "topic", 0, "send into this forum topic")
+	cmd.Flags().IntVar(&commentTo, "comment-to", 0, "comment on this channel post (sent to the linked discussion group)")
 	cmd.Flags().BoolVar(&silent, "silent", false, "send silently")
 	cmd.Flags().StringVar(&file, "file", "", "path to file to upload")
 	cmd.Flags().StringVar(&caption, "caption", "", "caption for uploaded media")
 	cmd.Flags().BoolVar(&voice, "voice", false, "send file as voice note (audio/ogg opus recommended)")
-	cmd.Flags().StringVar(&schedule, "schedule", "", "schedule time (RFC3339 or unix seconds)")
+	cmd.Flags().StringVar(&schedule, "schedule", "", "schedule time: RFC3339, unix seconds, +2h, 18:30 or \"tomorrow 09:00\"")
+	cmd.Flags().StringVar(&tz, "tz", "", "time zone for --schedule wall-clock times (IANA name, default local)")
+	cmd.Flags().StringVar(&media.Location, "location", "", "send a location as lat,lon")
+	cmd.Flags().StringVar(&media.LivePeriod, "live-period", "", "share --location live for this long (1m to 24h, or forever)")
+	cmd.Flags().StringVar(&media.Venue, "venue", "", "send a venue with this title at --location")
+	cmd.Flags().StringVar(&media.Address, "address", "", "venue address")
+	cmd.Flags().StringVar(&media.Contact, "contact", "", "send a contact with this phone number; the message text is the name")
+	cmd.Flags().StringVar(&media.Dice, "dice", "", "send an animated dice with this emoji: 🎲 🎯 🏀 ⚽ 🎳 🎰")
+	cmd.Flags().StringArrayVar(&markup.Buttons, "button", nil, "inline button row (bots): Text=url:<url>, Text=cb:<data>, Text=switch:<query> or Text=copy:<text>; separate buttons with |")
+	cmd.Flags().StringArrayVar(&markup.Keyboard, "keyboard", nil, "reply keyboard row (bots); separate buttons with |")
+	cmd.Flags().BoolVar(&markup.KeyboardOnce, "keyboard-once", false, "hide the reply keyboard after one use")
+	cmd.Flags().BoolVar(&markup.KeyboardResize, "keyboard-resize", false, "fit the reply keyboard to its buttons")
+	cmd.Flags().StringVar(&markup.Placeholder, "placeholder", "", "input placeholder for --keyboard or --force-reply")
+	cmd.Flags().BoolVar(&markup.RemoveKeyboard, "remove-keyboard", false, "remove the current reply keyboard (bots)")
+	cmd.Flags().BoolVar(&markup.ForceReply, "force-reply", false, "ask the recipient to reply (bots)")
 	return cmd
 }
 
-func parseSchedule(value string) (int, error) {
-	value = strings.TrimSpace(value)
-	if value == "" {
-		return 0, fmt.Errorf("schedule value is empty")
+// outgoingMessage is a text message, or a file or other media with Text as
+// its caption.
+type outgoingMessage struct {
+	Text     string
+	File     string
+	Media    tg.InputMediaClass
+	Markup   tg.ReplyMarkupClass
+	Voice    bool
+	Silent   bool
+	ReplyTo  tg.InputReplyToClass
+	Schedule int
+}
+
+func sendMessage(ctx context.Context, api *tg.Client, peer tg.InputPeerClass, m outgoingMessage) (tg.UpdatesClass, error) {
+	if m.File == "" && m.Media == nil {
+		req := &tg.MessagesSendMessageRequest{
+			Peer:     peer,
+			Message:  m.Text,
+			RandomID: rand.Int63(),
+			Silent:   m.Silent,
+		}
+		if m.Schedule != 0 {
+			req.ScheduleDate = m.Schedule
+		}
+		if m.ReplyTo != nil {
+			req.ReplyTo = m.ReplyTo
+		}
+		if m.Markup != nil {
+			req.SetReplyMarkup(m.Markup)
+		}
+		return api.MessagesSendMessage(ctx, req)
 	}
-	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
-		if unix > 1_000_000_000_000 {
-			unix = unix / 1000
+
+	media := m.Media
+	if m.File != "" {
+		var err error
+		media, err = uploadMedia(ctx, api, m.File, uploadOptions{AsVoice: m.Voice})
+		if err != nil {
+			return nil, err
 		}
-		return int(unix), nil
 	}
-	if t, err := time.Parse(time.RFC3339, value); err == nil {
-		return int(t.Unix()), nil
+	req := &tg.MessagesSendMediaRequest{
+		Peer:     peer,
+		Media:    media,
+		Message:  m.Text,
+		RandomID: rand.Int63(),
+		Silent:   m.Silent,
+	}
+	if m.Schedule != 0 {
+		req.ScheduleDate = m.Schedule
+	}
+	if m.ReplyTo != nil {
+		req.ReplyTo = m.ReplyTo
 	}
-	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
-		return int(t.Unix()), nil
+	if m.Markup != nil {
+		req.SetReplyMarkup(m.Markup)
 	}
-	return 0, fmt.Errorf("invalid schedule time: use RFC3339 or unix seconds")
+	return api.MessagesSendMedia(ctx, req)
 }
 
 type uploadOptions struct {
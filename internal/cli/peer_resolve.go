@@ -2,13 +2,65 @@ package cli
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"github.com/gotd/td/telegram/deeplink"
 	"github.com/gotd/td/telegram/peers"
+	"github.com/gotd/td/tg"
 )
 
 func resolvePeer(ctx context.Context, pm *peers.Manager, input string) (peers.Peer, error) {
 	if id, ok := parsePeerRef(input); ok {
 		return pm.ResolveTDLibID(ctx, id)
 	}
+	if hash, ok := inviteHash(input); ok {
+		return resolveInvite(ctx, pm, hash)
+	}
 	return pm.Resolve(ctx, input)
 }
+
+// inviteHash extracts the hash of t.me/+hash, t.me/joinchat/hash and tg:join links.
+func inviteHash(input string) (string, bool) {
+	s := strings.TrimSpace(input)
+	if !deeplink.IsDeeplinkLike(s) {
+		return "", false
+	}
+	link, err := deeplink.Expect(s, deeplink.Join)
+	if err != nil {
+		return "", false
+	}
+	return link.Args.Get("invite"), true
+}
+
+// resolveInvite returns the chat behind an invite link, if it is visible to us.
+func resolveInvite(ctx context.Context, pm *peers.Manager, hash string) (peers.Peer, error) {
+	invite, err := pm.API().MessagesCheckChatInvite(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	switch v := invite.(type) {
+	case *tg.ChatInviteAlready:
+		return invitePeer(ctx, pm, v.Chat)
+	case *tg.ChatInvitePeek:
+		return invitePeer(ctx, pm, v.Chat)
+	case *tg.ChatInvite:
+		return nil, fmt.Errorf("not a member of %q; join it with `chat join` first", v.Title)
+	default:
+		return nil, fmt.Errorf("unexpected invite %T", invite)
+	}
+}
+
+func invitePeer(ctx context.Context, pm *peers.Manager, chat tg.ChatClass) (peers.Peer, error) {
+	if err := pm.Apply(ctx, nil, []tg.ChatClass{chat}); err != nil {
+		return nil, err
+	}
+	switch v := chat.(type) {
+	case *tg.Chat:
+		return pm.Chat(v), nil
+	case *tg.Channel:
+		return pm.Channel(v), nil
+	default:
+		return nil, fmt.Errorf("invite points to an inaccessible chat")
+	}
+}
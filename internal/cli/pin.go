@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func newMessagePinCmd() *cobra.Command {
+	var (
+		silent    bool
+		pmOneside bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pin <peer> <id>",
+		Short: "Pin a message",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			msgID, err := parseMessageID(args[1])
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				updates, err := b.Client.API().MessagesUpdatePinnedMessage(ctx, &tg.MessagesUpdatePinnedMessageRequest{
+					Peer:      peer.InputPeer(),
+					ID:        msgID,
+					Silent:    silent,
+					PmOneside: pmOneside,
+				})
+				if err != nil {
+					return err
+				}
+
+				return rt.Printer.Render(types.SendResult{
+					OK:        true,
+					MessageID: msgID,
+					Updates:   fmt.Sprintf("%T", updates),
+				})
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&silent, "silent", false, "pin without notifying members")
+	cmd.Flags().BoolVar(&pmOneside, "pm-oneside", false, "in private chats, pin only for yourself")
+	return cmd
+}
+
+func newMessageUnpinCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "unpin <peer> [id]",
+		Short: "Unpin a message, or all messages with --all",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("peer is required")
+			}
+			if all && len(args) > 1 {
+				return fmt.Errorf("use a message id or --all, not both")
+			}
+			if !all && len(args) != 2 {
+				return fmt.Errorf("message id is required (or use --all)")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			var msgID int
+			if !all {
+				msgID, err = parseMessageID(args[1])
+				if err != nil {
+					return err
+				}
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				if all {
+					if err := drainAffected(func() (*tg.MessagesAffectedHistory, error) {
+						return b.Client.API().MessagesUnpinAllMessages(ctx, &tg.MessagesUnpinAllMessagesRequest{
+							Peer: peer.InputPeer(),
+						})
+					}); err != nil {
+						return err
+					}
+					return rt.Printer.Render(types.SendResult{OK: true})
+				}
+
+				updates, err := b.Client.API().MessagesUpdatePinnedMessage(ctx, &tg.MessagesUpdatePinnedMessageRequest{
+					Peer:  peer.InputPeer(),
+					ID:    msgID,
+					Unpin: true,
+				})
+				if err != nil {
+					return err
+				}
+
+				return rt.Printer.Render(types.SendResult{
+					OK:        true,
+					MessageID: msgID,
+					Updates:   fmt.Sprintf("%T", updates),
+				})
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "unpin all messages in the chat")
+	return cmd
+}
+
+func newChatPinnedCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "pinned <peer>",
+		Short: "List pinned messages",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				res, err := b.Client.API().MessagesSearch(ctx, &tg.MessagesSearchRequest{
+					Peer:   peer.InputPeer(),
+					Filter: &tg.InputMessagesFilterPinned{},
+					Limit:  limit,
+				})
+				if err != nil {
+					return err
+				}
+
+				messages, users, chats := extractMessages(res)
+				if err := b.Peers.Apply(ctx, users, chats); err != nil {
+					return err
+				}
+
+				items := buildMessageItems(messages, time.Time{})
+				resolveSenders(ctx, b.Peers, items)
+				return printMessages(rt, items)
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 20, "limit number of messages")
+	return cmd
+}
@@ -0,0 +1,456 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/telegram/peers"
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+// Telegram closes polls automatically only within this window.
+const (
+	minPollClose = 5 * time.Second
+	maxPollClose = 10 * time.Minute
+)
+
+type pollSpec struct {
+	Question    string
+	Options     []string
+	Multiple    bool
+	Quiz        bool
+	Correct     int
+	Explanation string
+	Public      bool
+	CloseIn     time.Duration
+}
+
+func newMessagePollCmd() *cobra.Command {
+	var (
+		spec      pollSpec
+		anonymous bool
+		silent    bool
+		topicID   int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "poll <peer> <question>",
+		Short: "Send a poll or quiz",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			spec.Question = args[1]
+			spec.Public = !anonymous
+			media, err := buildPoll(spec)
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				updates, err := sendMessage(ctx, b.Client.API(), peer.InputPeer(), outgoingMessage{
+					Media:   media,
+					Silent:  silent,
+					ReplyTo: inputReplyTo(0, topicID),
+				})
+				if err != nil {
+					return err
+				}
+				result := types.SendResult{OK: true, Updates: fmt.Sprintf("%T", updates)}
+				if id, ok := extractSentMessageID(updates); ok {
+					result.MessageID = id
+				}
+				return rt.Printer.Render(result)
+			})
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&spec.Options, "option", nil, "answer option (repeat for each, at least 2)")
+	cmd.Flags().BoolVar(&spec.Multiple, "multiple", false, "allow several answers")
+	cmd.Flags().BoolVar(&spec.Quiz, "quiz", false, "quiz mode with one correct answer (requires --correct)")
+	cmd.Flags().IntVar(&spec.Correct, "correct", 0, "correct option for --quiz (1-based)")
+	cmd.Flags().StringVar(&spec.Explanation, "explanation", "", "shown after a wrong quiz answer")
+	cmd.Flags().BoolVar(&anonymous, "anonymous", true, "hide who voted for what")
+	cmd.Flags().DurationVar(&spec.CloseIn, "close-in", 0, "close the poll automatically after this long (5s to 10m; use message poll-close for longer)")
+	cmd.Flags().BoolVar(&silent, "silent", false, "send silently")
+	cmd.Flags().IntVar(&topicID, "topic", 0, "send into this forum topic")
+	return cmd
+}
+
+func newMessageVoteCmd() *cobra.Command {
+	var retract bool
+
+	cmd := &cobra.Command{
+		Use:   "vote <peer> <id> [option...]",
+		Short: "Vote in a poll",
+		Long: `Vote in a poll. Options are 1-based numbers or the exact answer text;
+several options need a multiple-answer poll. --retract takes back your vote.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return fmt.Errorf("peer and message id are required")
+			}
+			if retract != (len(args) == 2) {
+				return fmt.Errorf("give options to vote for, or --retract")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			msgID, err := parseMessageID(args[1])
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+				api := b.Client.API()
+
+				media, err := getPoll(ctx, api, peer, msgID)
+				if err != nil {
+					return err
+				}
+				if media.Poll.Closed {
+					return fmt.Errorf("poll is closed")
+				}
+				options, err := pollOptions(media.Poll, args[2:])
+				if err != nil {
+					return err
+				}
+
+				if _, err := api.MessagesSendVote(ctx, &tg.MessagesSendVoteRequest{
+					Peer:    peer.InputPeer(),
+					MsgID:   msgID,
+					Options: options,
+				}); err != nil {
+					return err
+				}
+				return rt.Printer.Render(types.SendResult{OK: true, MessageID: msgID})
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&retract, "retract", false, "take back your vote")
+	return cmd
+}
+
+func newMessagePollResultsCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "poll-results <peer> <id>",
+		Short: "Show poll results (with voters for public polls)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			msgID, err := parseMessageID(args[1])
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+				api := b.Client.API()
+
+				media, err := getPoll(ctx, api, peer, msgID)
+				if err != nil {
+					return err
+				}
+				results := media.Results
+				updates, err := api.MessagesGetPollResults(ctx, &tg.MessagesGetPollResultsRequest{
+					Peer:  peer.InputPeer(),
+					MsgID: msgID,
+				})
+				if err != nil {
+					return err
+				}
+				if fresh, ok := pollResultsUpdate(updates, media.Poll.ID); ok {
+					results = fresh
+				}
+
+				var voters map[string][]types.PollVoter
+				if media.Poll.PublicVoters && limit > 0 {
+					voters, err = loadPollVoters(ctx, b, peer.InputPeer(), msgID, limit)
+					if err != nil {
+						return err
+					}
+				}
+				return rt.Printer.Render(pollOptionItems(media.Poll, results, voters))
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 100, "max number of voters to list (public polls; 0 skips voters)")
+	return cmd
+}
+
+func newMessagePollCloseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "poll-close <peer> <id>",
+		Short: "Close a poll you sent",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			msgID, err := parseMessageID(args[1])
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+				api := b.Client.API()
+
+				media, err := getPoll(ctx, api, peer, msgID)
+				if err != nil {
+					return err
+				}
+				if !media.Poll.Closed {
+					poll := media.Poll
+					poll.Closed = true
+					if _, err := api.MessagesEditMessage(ctx, &tg.MessagesEditMessageRequest{
+						Peer:  peer.InputPeer(),
+						ID:    msgID,
+						Media: &tg.InputMediaPoll{Poll: poll},
+					}); err != nil {
+						return err
+					}
+				}
+				return rt.Printer.Render(types.SendResult{OK: true, MessageID: msgID})
+			})
+		},
+	}
+	return cmd
+}
+
+// buildPoll validates a poll and builds its media. Option bytes are the
+// 0-based option index.
+func buildPoll(spec pollSpec) (*tg.InputMediaPoll, error) {
+	if strings.TrimSpace(spec.Question) == "" {
+		return nil, fmt.Errorf("poll question cannot be empty")
+	}
+	if len(spec.Options) < 2 {
+		return nil, fmt.Errorf("a poll needs at least 2 --option values")
+	}
+	switch {
+	case spec.Quiz && spec.Multiple:
+		return nil, fmt.Errorf("a quiz cannot allow multiple answers")
+	case spec.Quiz && (spec.Correct < 1 || spec.Correct > len(spec.Options)):
+		return nil, fmt.Errorf("--quiz needs --correct between 1 and %d", len(spec.Options))
+	case !spec.Quiz && spec.Correct != 0:
+		return nil, fmt.Errorf("--correct requires --quiz")
+	case !spec.Quiz && spec.Explanation != "":
+		return nil, fmt.Errorf("--explanation requires --quiz")
+	case spec.CloseIn != 0 && (spec.CloseIn < minPollClose || spec.CloseIn > maxPollClose):
+		return nil, fmt.Errorf("--close-in must be between 5s and 10m (Telegram's limit); close later polls with message poll-close")
+	}
+
+	poll := tg.Poll{
+		Question:       tg.TextWithEntities{Text: spec.Question},
+		PublicVoters:   spec.Public,
+		MultipleChoice: spec.Multiple,
+		Quiz:           spec.Quiz,
+	}
+	for i, text := range spec.Options {
+		if strings.TrimSpace(text) == "" {
+			return nil, fmt.Errorf("option %d is empty", i+1)
+		}
+		poll.Answers = append(poll.Answers, tg.PollAnswer{
+			Text:   tg.TextWithEntities{Text: text},
+			Option: []byte(strconv.Itoa(i)),
+		})
+	}
+	if spec.CloseIn != 0 {
+		poll.SetClosePeriod(int(spec.CloseIn / time.Second))
+	}
+
+	media := &tg.InputMediaPoll{Poll: poll}
+	if spec.Quiz {
+		media.SetCorrectAnswers([][]byte{poll.Answers[spec.Correct-1].Option})
+		if spec.Explanation != "" {
+			media.SetSolution(spec.Explanation)
+		}
+	}
+	return media, nil
+}
+
+// pollOptions maps 1-based numbers or answer texts to option bytes.
+func pollOptions(poll tg.Poll, choices []string) ([][]byte, error) {
+	if len(choices) > 1 && !poll.MultipleChoice {
+		return nil, fmt.Errorf("poll allows only one answer")
+	}
+	options := make([][]byte, 0, len(choices))
+	for _, choice := range choices {
+		idx := -1
+		if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(poll.Answers) {
+			idx = n - 1
+		} else {
+			for i, a := range poll.Answers {
+				if strings.EqualFold(strings.TrimSpace(a.Text.Text), strings.TrimSpace(choice)) {
+					idx = i
+					break
+				}
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("no option %q (use 1-%d or the answer text)", choice, len(poll.Answers))
+		}
+		options = append(options, poll.Answers[idx].Option)
+	}
+	return options, nil
+}
+
+func getPoll(ctx context.Context, api *tg.Client, peer peers.Peer, msgID int) (*tg.MessageMediaPoll, error) {
+	msg, err := getMessage(ctx, api, peer, msgID)
+	if err != nil {
+		return nil, err
+	}
+	media, ok := msg.Media.(*tg.MessageMediaPoll)
+	if !ok {
+		return nil, fmt.Errorf("message %d is not a poll", msgID)
+	}
+	return media, nil
+}
+
+func getMessage(ctx context.Context, api *tg.Client, peer peers.Peer, msgID int) (*tg.Message, error) {
+	ids := []tg.InputMessageClass{&tg.InputMessageID{ID: msgID}}
+	var (
+		res tg.MessagesMessagesClass
+		err error
+	)
+	if ch, ok := peer.(peers.Channel); ok {
+		res, err = api.ChannelsGetMessages(ctx, &tg.ChannelsGetMessagesRequest{Channel: ch.InputChannel(), ID: ids})
+	} else {
+		res, err = api.MessagesGetMessages(ctx, ids)
+	}
+	if err != nil {
+		return nil, err
+	}
+	messages, _, _ := extractMessages(res)
+	for _, m := range messages {
+		if msg, ok := m.(*tg.Message); ok && msg.ID == msgID {
+			return msg, nil
+		}
+	}
+	return nil, fmt.Errorf("message %d not found", msgID)
+}
+
+func pollResultsUpdate(updates tg.UpdatesClass, pollID int64) (tg.PollResults, bool) {
+	var list []tg.UpdateClass
+	switch u := updates.(type) {
+	case *tg.Updates:
+		list = u.Updates
+	case *tg.UpdatesCombined:
+		list = u.Updates
+	}
+	for _, upd := range list {
+		if u, ok := upd.(*tg.UpdateMessagePoll); ok && u.PollID == pollID {
+			return u.Results, true
+		}
+	}
+	return tg.PollResults{}, false
+}
+
+// loadPollVoters returns voters keyed by option bytes.
+func loadPollVoters(ctx context.Context, b *tgclient.Bundle, peer tg.InputPeerClass, msgID, limit int) (map[string][]types.PollVoter, error) {
+	voters := make(map[string][]types.PollVoter)
+	req := &tg.MessagesGetPollVotesRequest{Peer: peer, ID: msgID}
+	for seen := 0; seen < limit; {
+		req.Limit = min(limit-seen, 100)
+		res, err := b.Client.API().MessagesGetPollVotes(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.Peers.Apply(ctx, res.Users, res.Chats); err != nil {
+			return nil, err
+		}
+
+		userMap, chatMap, channelMap := buildPeerMaps(res.Users, res.Chats)
+		for _, v := range res.Votes {
+			var options [][]byte
+			switch vote := v.(type) {
+			case *tg.MessagePeerVote:
+				options = [][]byte{vote.Option}
+			case *tg.MessagePeerVoteMultiple:
+				options = vote.Options
+			}
+			voter := types.PollVoter{Date: unixTime(v.GetDate())}
+			if id, ok := peerIDFromPeerClass(v.GetPeer()); ok {
+				voter.PeerRef = peerRefFromID(id)
+			}
+			if p := peerFromDialog(b.Peers, v.GetPeer(), userMap, chatMap, channelMap); p != nil {
+				voter.Name = p.VisibleName()
+				voter.Username, _ = p.Username()
+			}
+			for _, opt := range options {
+				voters[string(opt)] = append(voters[string(opt)], voter)
+			}
+		}
+		seen += len(res.Votes)
+
+		if res.NextOffset == "" || len(res.Votes) == 0 {
+			break
+		}
+		req.SetOffset(res.NextOffset)
+	}
+	return voters, nil
+}
+
+func pollOptionItems(poll tg.Poll, results tg.PollResults, voters map[string][]types.PollVoter) []types.PollOptionItem {
+	counts := make(map[string]tg.PollAnswerVoters, len(results.Results))
+	for _, r := range results.Results {
+		counts[string(r.Option)] = r
+	}
+
+	items := make([]types.PollOptionItem, 0, len(poll.Answers))
+	for i, a := range poll.Answers {
+		r := counts[string(a.Option)]
+		item := types.PollOptionItem{
+			Option:  i + 1,
+			Text:    a.Text.Text,
+			Votes:   r.Voters,
+			Chosen:  r.Chosen,
+			Correct: r.Correct,
+			Voters:  voters[string(a.Option)],
+		}
+		if results.TotalVoters > 0 {
+			item.Percent = r.Voters * 100 / results.TotalVoters
+		}
+		items = append(items, item)
+	}
+	return items
+}
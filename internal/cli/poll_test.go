@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func TestBuildPoll(t *testing.T) {
+	media, err := buildPoll(pollSpec{
+		Question:    "Lunch?",
+		Options:     []string{"Pizza", "Sushi", "Salad"},
+		Quiz:        true,
+		Correct:     2,
+		Explanation: "Always sushi",
+		Public:      true,
+		CloseIn:     time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	poll := media.Poll
+	if poll.Question.Text != "Lunch?" || len(poll.Answers) != 3 || !poll.Quiz || !poll.PublicVoters {
+		t.Fatalf("unexpected poll: %+v", poll)
+	}
+	if poll.ClosePeriod != 60 {
+		t.Fatalf("expected close period 60, got %d", poll.ClosePeriod)
+	}
+	if len(media.CorrectAnswers) != 1 || string(media.CorrectAnswers[0]) != "1" || media.Solution != "Always sushi" {
+		t.Fatalf("unexpected quiz answer: %q %q", media.CorrectAnswers, media.Solution)
+	}
+
+	bad := []pollSpec{
+		{Question: " ", Options: []string{"a", "b"}},
+		{Question: "q", Options: []string{"a"}},
+		{Question: "q", Options: []string{"a", " "}},
+		{Question: "q", Options: []string{"a", "b"}, Quiz: true},
+		{Question: "q", Options: []string{"a", "b"}, Quiz: true, Correct: 3},
+		{Question: "q", Options: []string{"a", "b"}, Quiz: true, Correct: 1, Multiple: true},
+		{Question: "q", Options: []string{"a", "b"}, Correct: 1},
+		{Question: "q", Options: []string{"a", "b"}, Explanation: "x"},
+		{Question: "q", Options: []string{"a", "b"}, CloseIn: time.Hour},
+		{Question: "q", Options: []string{"a", "b"}, CloseIn: time.Second},
+	}
+	for i, spec := range bad {
+		if _, err := buildPoll(spec); err == nil {
+			t.Fatalf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestPollOptions(t *testing.T) {
+	media, err := buildPoll(pollSpec{Question: "q", Options: []string{"Yes", "No", "Maybe"}, Multiple: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	poll := media.Poll
+
+	got, err := pollOptions(poll, []string{"1", "maybe"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || string(got[0]) != "0" || string(got[1]) != "2" {
+		t.Fatalf("unexpected options %q", got)
+	}
+	if got, err := pollOptions(poll, nil); err != nil || len(got) != 0 {
+		t.Fatalf("retract: unexpected %q %v", got, err)
+	}
+	if _, err := pollOptions(poll, []string{"4"}); err == nil {
+		t.Fatalf("expected error for unknown option")
+	}
+	poll.MultipleChoice = false
+	if _, err := pollOptions(poll, []string{"1", "2"}); err == nil {
+		t.Fatalf("expected error for several answers")
+	}
+}
+
+func TestPollOptionItems(t *testing.T) {
+	media, err := buildPoll(pollSpec{Question: "q", Options: []string{"Yes", "No"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := tg.PollResults{
+		TotalVoters: 3,
+		Results: []tg.PollAnswerVoters{
+			{Option: []byte("0"), Voters: 2, Chosen: true},
+			{Option: []byte("1"), Voters: 1},
+		},
+	}
+	voters := map[string][]types.PollVoter{"1": {{PeerRef: "u1", Name: "Jane"}}}
+
+	items := pollOptionItems(media.Poll, results, voters)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if items[0].Option != 1 || items[0].Text != "Yes" || items[0].Votes != 2 || items[0].Percent != 66 || !items[0].Chosen {
+		t.Fatalf("unexpected first item: %+v", items[0])
+	}
+	if items[1].Percent != 33 || len(items[1].Voters) != 1 || items[1].Voters[0].String() != "Jane" {
+		t.Fatalf("unexpected second item: %+v", items[1])
+	}
+}
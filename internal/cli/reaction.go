@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func newMessageReactCmd() *cobra.Command {
+	var (
+		remove bool
+		big    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "react <peer> <id> [emoji...]",
+		Short: "React to a message",
+		Long: `React to a message with one or more emoji.
+
+A numeric value is sent as a custom emoji (document ID). --remove clears your
+reactions on the message.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return fmt.Errorf("peer and message id are required")
+			}
+			if remove && len(args) > 2 {
+				return fmt.Errorf("--remove does not take reactions")
+			}
+			if !remove && len(args) < 3 {
+				return fmt.Errorf("at least one reaction is required")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			msgID, err := parseMessageID(args[1])
+			if err != nil {
+				return err
+			}
+			reactions := make([]tg.ReactionClass, 0, len(args)-2)
+			for _, value := range args[2:] {
+				reactions = append(reactions, parseReaction(value))
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				updates, err := b.Client.API().MessagesSendReaction(ctx, &tg.MessagesSendReactionRequest{
+					Peer:        peer.InputPeer(),
+					MsgID:       msgID,
+					Reaction:    reactions,
+					Big:         big,
+					AddToRecent: !remove,
+				})
+				if err != nil {
+					return err
+				}
+
+				return rt.Printer.Render(types.SendResult{
+					OK:        true,
+					MessageID: msgID,
+					Updates:   fmt.Sprintf("%T", updates),
+				})
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&remove, "remove", false, "remove your reactions")
+	cmd.Flags().BoolVar(&big, "big", false, "play the big reaction animation")
+	return cmd
+}
+
+func newMessageReactionsCmd() *cobra.Command {
+	var (
+		reaction string
+		limit    int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "reactions <peer> <id>",
+		Short: "List who reacted to a message",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			msgID, err := parseMessageID(args[1])
+			if err != nil {
+				return err
+			}
+			if limit <= 0 {
+				return fmt.Errorf("--limit must be positive")
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				req := &tg.MessagesGetMessageReactionsListRequest{
+					Peer: peer.InputPeer(),
+					ID:   msgID,
+				}
+				if reaction != "" {
+					req.SetReaction(parseReaction(reaction))
+				}
+
+				items := make([]types.ReactionItem, 0)
+				for len(items) < limit {
+					req.Limit = min(limit-len(items), 100)
+					res, err := b.Client.API().MessagesGetMessageReactionsList(ctx, req)
+					if err != nil {
+						return err
+					}
+					if err := b.Peers.Apply(ctx, res.Users, res.Chats); err != nil {
+						return err
+					}
+
+					userMap, chatMap, channelMap := buildPeerMaps(res.Users, res.Chats)
+					for _, r := range res.Reactions {
+						item := types.ReactionItem{
+							Reaction: formatReaction(r.Reaction),
+							Date:     time.Unix(int64(r.Date), 0),
+							Big:      r.Big,
+							My:       r.My,
+						}
+						if id, ok := peerIDFromPeerClass(r.PeerID); ok {
+							item.PeerRef = peerRefFromID(id)
+						}
+						if p := peerFromDialog(b.Peers, r.PeerID, userMap, chatMap, channelMap); p != nil {
+							item.Name = p.VisibleName()
+							item.Username, _ = p.Username()
+						}
+						items = append(items, item)
+					}
+
+					if res.NextOffset == "" || len(res.Reactions) == 0 {
+						break
+					}
+					req.SetOffset(res.NextOffset)
+				}
+
+				return rt.Printer.Render(items)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&reaction, "reaction", "", "only list this reaction")
+	cmd.Flags().IntVar(&limit, "limit", 50, "max number of reactions")
+	return cmd
+}
+
+// parseReaction treats numeric values as custom emoji document IDs.
+func parseReaction(value string) tg.ReactionClass {
+	if id, err := strconv.ParseInt(value, 10, 64); err == nil && id > 0 {
+		return &tg.ReactionCustomEmoji{DocumentID: id}
+	}
+	return &tg.ReactionEmoji{Emoticon: value}
+}
+
+func formatReaction(r tg.ReactionClass) string {
+	switch v := r.(type) {
+	case *tg.ReactionEmoji:
+		return v.Emoticon
+	case *tg.ReactionCustomEmoji:
+		return strconv.FormatInt(v.DocumentID, 10)
+	case *tg.ReactionPaid:
+		return "⭐"
+	default:
+		return ""
+	}
+}
+
+func reactionCounts(m *tg.Message) []types.ReactionCount {
+	reactions, ok := m.GetReactions()
+	if !ok || len(reactions.Results) == 0 {
+		return nil
+	}
+	out := make([]types.ReactionCount, 0, len(reactions.Results))
+	for _, r := range reactions.Results {
+		_, chosen := r.GetChosenOrder()
+		out = append(out, types.ReactionCount{
+			Reaction: formatReaction(r.Reaction),
+			Count:    r.Count,
+			Chosen:   chosen,
+		})
+	}
+	return out
+}
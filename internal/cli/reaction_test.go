@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func TestParseReaction(t *testing.T) {
+	if got, ok := parseReaction("👍").(*tg.ReactionEmoji); !ok || got.Emoticon != "👍" {
+		t.Fatalf("expected emoji reaction, got %#v", parseReaction("👍"))
+	}
+	if got, ok := parseReaction("5368324170671202286").(*tg.ReactionCustomEmoji); !ok || got.DocumentID != 5368324170671202286 {
+		t.Fatalf("expected custom emoji reaction, got %#v", parseReaction("5368324170671202286"))
+	}
+	if got := formatReaction(parseReaction("5368324170671202286")); got != "5368324170671202286" {
+		t.Fatalf("formatReaction() = %q", got)
+	}
+}
+
+func TestReactionCounts(t *testing.T) {
+	msg := &tg.Message{}
+	if got := reactionCounts(msg); got != nil {
+		t.Fatalf("expected no reactions, got %v", got)
+	}
+
+	chosen := tg.ReactionCount{Reaction: &tg.ReactionEmoji{Emoticon: "🔥"}, Count: 3}
+	chosen.SetChosenOrder(0)
+	msg.SetReactions(tg.MessageReactions{Results: []tg.ReactionCount{
+		chosen,
+		{Reaction: &tg.ReactionCustomEmoji{DocumentID: 42}, Count: 1},
+	}})
+
+	want := []types.ReactionCount{
+		{Reaction: "🔥", Count: 3, Chosen: true},
+		{Reaction: "42", Count: 1},
+	}
+	if got := reactionCounts(msg); !reflect.DeepEqual(got, want) {
+		t.Fatalf("reactionCounts() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/constant"
+	"github.com/gotd/td/telegram/peers"
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func newChatReadCmd() *cobra.Command {
+	var (
+		maxID int
+		all   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "read <peer>",
+		Short: "Mark a chat (or all chats with --all) as read",
+		Long: `Mark a chat as read, including unread mentions, unread reactions and the
+manual unread mark. --max-id limits which messages are marked read.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if all {
+				if len(args) > 0 {
+					return fmt.Errorf("use a peer or --all, not both")
+				}
+				if maxID != 0 {
+					return fmt.Errorf("--max-id cannot be used with --all")
+				}
+				return nil
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				api := b.Client.API()
+
+				if !all {
+					peer, err := resolvePeer(ctx, b.Peers, args[0])
+					if err != nil {
+						return err
+					}
+					dialog, err := peerDialog(ctx, api, peer)
+					if err != nil {
+						return err
+					}
+					result, err := markRead(ctx, api, peer, dialogItem(peer, dialog), maxID)
+					if err != nil {
+						return err
+					}
+					return rt.Printer.Render(result)
+				}
+
+				var dialogs []types.ChatListItem
+				for _, folder := range []int{0, archiveFolderID} {
+					list, err := loadDialogs(ctx, b, dialogQuery{FolderID: folder, Match: hasUnread})
+					if err != nil {
+						return err
+					}
+					dialogs = append(dialogs, list...)
+				}
+				results := make([]types.ReadResult, 0, len(dialogs))
+				for _, d := range dialogs {
+					peer, err := b.Peers.ResolveTDLibID(ctx, constant.TDLibPeerID(d.PeerID))
+					if err != nil {
+						return err
+					}
+					result, err := markRead(ctx, api, peer, d, 0)
+					if err != nil {
+						return fmt.Errorf("%s: %w", d.PeerRef, err)
+					}
+					results = append(results, result)
+				}
+				return rt.Printer.Render(results)
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&maxID, "max-id", 0, "mark messages up to this id as read")
+	cmd.Flags().BoolVar(&all, "all", false, "mark all chats with unread messages as read")
+	return cmd
+}
+
+func newChatUnreadCmd() *cobra.Command {
+	var remove bool
+
+	cmd := &cobra.Command{
+		Use:   "unread <peer>",
+		Short: "Mark a chat as unread",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				if _, err := b.Client.API().MessagesMarkDialogUnread(ctx, &tg.MessagesMarkDialogUnreadRequest{
+					Peer:   &tg.InputDialogPeer{Peer: peer.InputPeer()},
+					Unread: !remove,
+				}); err != nil {
+					return err
+				}
+				return rt.Printer.Render(types.SendResult{OK: true})
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&remove, "clear", false, "remove the unread mark instead")
+	return cmd
+}
+
+func peerDialog(ctx context.Context, api *tg.Client, peer peers.Peer) (*tg.Dialog, error) {
+	res, err := api.MessagesGetPeerDialogs(ctx, []tg.InputDialogPeerClass{
+		&tg.InputDialogPeer{Peer: peer.InputPeer()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range res.Dialogs {
+		if dialog, ok := d.(*tg.Dialog); ok {
+			return dialog, nil
+		}
+	}
+	return nil, fmt.Errorf("no dialog with %s", peer.VisibleName())
+}
+
+// markRead clears the unread state of a dialog and reports what was unread before.
+func markRead(ctx context.Context, api *tg.Client, peer peers.Peer, d types.ChatListItem, maxID int) (types.ReadResult, error) {
+	result := types.ReadResult{
+		PeerRef:   d.PeerRef,
+		Title:     d.Title,
+		MaxID:     maxID,
+		Unread:    d.UnreadCount,
+		Mentions:  d.UnreadMentions,
+		Reactions: d.UnreadReactions,
+	}
+
+	if d.UnreadCount > 0 || maxID != 0 {
+		if err := readHistory(ctx, api, peer, maxID); err != nil {
+			return result, err
+		}
+	}
+	if d.UnreadMentions > 0 {
+		if err := drainAffected(func() (*tg.MessagesAffectedHistory, error) {
+			return api.MessagesReadMentions(ctx, &tg.MessagesReadMentionsRequest{Peer: peer.InputPeer()})
+		}); err != nil {
+			return result, err
+		}
+	}
+	if d.UnreadReactions > 0 {
+		if err := drainAffected(func() (*tg.MessagesAffectedHistory, error) {
+			return api.MessagesReadReactions(ctx, &tg.MessagesReadReactionsRequest{Peer: peer.InputPeer()})
+		}); err != nil {
+			return result, err
+		}
+	}
+	if d.UnreadMark {
+		if _, err := api.MessagesMarkDialogUnread(ctx, &tg.MessagesMarkDialogUnreadRequest{
+			Peer: &tg.InputDialogPeer{Peer: peer.InputPeer()},
+		}); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// drainAffected repeats a partial-history call until the server reports no
+// remaining offset.
+func drainAffected(call func() (*tg.MessagesAffectedHistory, error)) error {
+	for {
+		res, err := call()
+		if err != nil {
+			return err
+		}
+		if res.Offset == 0 {
+			return nil
+		}
+	}
+}
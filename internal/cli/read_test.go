@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestDrainAffected(t *testing.T) {
+	offsets := []int{30, 10, 0, 5}
+	calls := 0
+	err := drainAffected(func() (*tg.MessagesAffectedHistory, error) {
+		res := &tg.MessagesAffectedHistory{Offset: offsets[calls]}
+		calls++
+		return res, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+
+	boom := errors.New("boom")
+	if err := drainAffected(func() (*tg.MessagesAffectedHistory, error) { return nil, boom }); !errors.Is(err, boom) {
+		t.Fatalf("expected boom, got %v", err)
+	}
+}
@@ -0,0 +1,334 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gotd/td/telegram/peers"
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+// adminScope is the kind of chat an admin right means something in.
+type adminScope int
+
+const (
+	scopeBoth adminScope = iota
+	scopeGroup
+	scopeChannel
+)
+
+type adminRight struct {
+	name  string
+	field func(r *tg.ChatAdminRights) *bool
+	scope adminScope
+}
+
+var adminRights = []adminRight{
+	{"change_info", func(r *tg.ChatAdminRights) *bool { return &r.ChangeInfo }, scopeBoth},
+	{"post_messages", func(r *tg.ChatAdminRights) *bool { return &r.PostMessages }, scopeChannel},
+	{"edit_messages", func(r *tg.ChatAdminRights) *bool { return &r.EditMessages }, scopeChannel},
+	{"delete_messages", func(r *tg.ChatAdminRights) *bool { return &r.DeleteMessages }, scopeBoth},
+	{"ban_users", func(r *tg.ChatAdminRights) *bool { return &r.BanUsers }, scopeBoth},
+	{"invite_users", func(r *tg.ChatAdminRights) *bool { return &r.InviteUsers }, scopeBoth},
+	{"pin_messages", func(r *tg.ChatAdminRights) *bool { return &r.PinMessages }, scopeGroup},
+	{"add_admins", func(r *tg.ChatAdminRights) *bool { return &r.AddAdmins }, scopeBoth},
+	{"anonymous", func(r *tg.ChatAdminRights) *bool { return &r.Anonymous }, scopeGroup},
+	{"manage_call", func(r *tg.ChatAdminRights) *bool { return &r.ManageCall }, scopeBoth},
+	{"other", func(r *tg.ChatAdminRights) *bool { return &r.Other }, scopeBoth},
+	{"manage_topics", func(r *tg.ChatAdminRights) *bool { return &r.ManageTopics }, scopeGroup},
+	{"post_stories", func(r *tg.ChatAdminRights) *bool { return &r.PostStories }, scopeChannel},
+	{"edit_stories", func(r *tg.ChatAdminRights) *bool { return &r.EditStories }, scopeChannel},
+	{"delete_stories", func(r *tg.ChatAdminRights) *bool { return &r.DeleteStories }, scopeChannel},
+	{"manage_direct_messages", func(r *tg.ChatAdminRights) *bool { return &r.ManageDirectMessages }, scopeChannel},
+}
+
+type bannedRight struct {
+	name  string
+	field func(r *tg.ChatBannedRights) *bool
+}
+
+var bannedRights = []bannedRight{
+	{"view_messages", func(r *tg.ChatBannedRights) *bool { return &r.ViewMessages }},
+	{"send_messages", func(r *tg.ChatBannedRights) *bool { return &r.SendMessages }},
+	{"send_media", func(r *tg.ChatBannedRights) *bool { return &r.SendMedia }},
+	{"send_stickers", func(r *tg.ChatBannedRights) *bool { return &r.SendStickers }},
+	{"send_gifs", func(r *tg.ChatBannedRights) *bool { return &r.SendGifs }},
+	{"send_games", func(r *tg.ChatBannedRights) *bool { return &r.SendGames }},
+	{"send_inline", func(r *tg.ChatBannedRights) *bool { return &r.SendInline }},
+	{"embed_links", func(r *tg.ChatBannedRights) *bool { return &r.EmbedLinks }},
+	{"send_polls", func(r *tg.ChatBannedRights) *bool { return &r.SendPolls }},
+	{"change_info", func(r *tg.ChatBannedRights) *bool { return &r.ChangeInfo }},
+	{"invite_users", func(r *tg.ChatBannedRights) *bool { return &r.InviteUsers }},
+	{"pin_messages", func(r *tg.ChatBannedRights) *bool { return &r.PinMessages }},
+	{"manage_topics", func(r *tg.ChatBannedRights) *bool { return &r.ManageTopics }},
+	{"send_photos", func(r *tg.ChatBannedRights) *bool { return &r.SendPhotos }},
+	{"send_videos", func(r *tg.ChatBannedRights) *bool { return &r.SendVideos }},
+	{"send_roundvideos", func(r *tg.ChatBannedRights) *bool { return &r.SendRoundvideos }},
+	{"send_audios", func(r *tg.ChatBannedRights) *bool { return &r.SendAudios }},
+	{"send_voices", func(r *tg.ChatBannedRights) *bool { return &r.SendVoices }},
+	{"send_docs", func(r *tg.ChatBannedRights) *bool { return &r.SendDocs }},
+	{"send_plain", func(r *tg.ChatBannedRights) *bool { return &r.SendPlain }},
+}
+
+// bannedRightAliases maps the names used in Telegram apps to API names.
+var bannedRightAliases = map[string]string{
+	"send_links": "embed_links",
+}
+
+// parseAdminRights accepts right names or "all", which grants the rights of
+// a channel when broadcast is set and those of a supergroup otherwise.
+func parseAdminRights(names []string, broadcast bool) (tg.ChatAdminRights, error) {
+	var rights tg.ChatAdminRights
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if name == "all" {
+			for _, r := range adminRights {
+				if r.scope == scopeBoth || (r.scope == scopeChannel) == broadcast {
+					*r.field(&rights) = true
+				}
+			}
+			rights.Anonymous = false
+			continue
+		}
+		found := false
+		for _, r := range adminRights {
+			if r.name == name {
+				*r.field(&rights) = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return rights, fmt.Errorf("unknown admin right %q (available: %s)", name, strings.Join(adminRightNames(), ", "))
+		}
+	}
+	return rights, nil
+}
+
+func formatAdminRights(rights tg.ChatAdminRights) []string {
+	var out []string
+	for _, r := range adminRights {
+		if *r.field(&rights) {
+			out = append(out, r.name)
+		}
+	}
+	return out
+}
+
+func adminRightNames() []string {
+	names := make([]string, 0, len(adminRights))
+	for _, r := range adminRights {
+		names = append(names, r.name)
+	}
+	return names
+}
+
+func parseBannedRights(names []string) (tg.ChatBannedRights, error) {
+	var rights tg.ChatBannedRights
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if alias, ok := bannedRightAliases[name]; ok {
+			name = alias
+		}
+		found := false
+		for _, r := range bannedRights {
+			if r.name == name {
+				*r.field(&rights) = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return rights, fmt.Errorf("unknown permission %q (available: %s)", name, strings.Join(bannedRightNames(), ", "))
+		}
+	}
+	return rights, nil
+}
+
+func formatBannedRights(rights tg.ChatBannedRights) []string {
+	var out []string
+	for _, r := range bannedRights {
+		if *r.field(&rights) {
+			out = append(out, r.name)
+		}
+	}
+	return out
+}
+
+func bannedRightNames() []string {
+	names := make([]string, 0, len(bannedRights)+len(bannedRightAliases))
+	for _, r := range bannedRights {
+		names = append(names, r.name)
+	}
+	for alias := range bannedRightAliases {
+		names = append(names, alias)
+	}
+	return names
+}
+
+func newMemberPromoteCmd() *cobra.Command {
+	var (
+		rights []string
+		rank   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "promote <peer> <user>",
+		Short: "Make a member an admin",
+		Long: `Make a member an admin with the given rights.
+
+Rights: ` + strings.Join(adminRightNames(), ", ") + `.
+"all" grants every right that applies to the chat (channel or supergroup)
+except anonymous. Basic groups only support full admins.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			granted, err := parseAdminRights(rights, false)
+			if err != nil {
+				return err
+			}
+			if len(formatAdminRights(granted)) == 0 {
+				return fmt.Errorf("--rights is required (e.g. --rights delete_messages,ban_users or --rights all)")
+			}
+
+			return runMemberAction(cmd, args, func(ctx context.Context, api *tg.Client, chat peers.Peer, user peers.User) error {
+				switch p := chat.(type) {
+				case peers.Channel:
+					granted, err := parseAdminRights(rights, p.IsBroadcast())
+					if err != nil {
+						return err
+					}
+					_, err = api.ChannelsEditAdmin(ctx, &tg.ChannelsEditAdminRequest{
+						Channel:     p.InputChannel(),
+						UserID:      user.InputUser(),
+						AdminRights: granted,
+						Rank:        rank,
+					})
+					return err
+				case peers.Chat:
+					if rank != "" {
+						return fmt.Errorf("--rank requires a supergroup or channel")
+					}
+					_, err := api.MessagesEditChatAdmin(ctx, &tg.MessagesEditChatAdminRequest{
+						ChatID:  p.ID(),
+						UserID:  user.InputUser(),
+						IsAdmin: true,
+					})
+					return err
+				default:
+					return fmt.Errorf("%s is not a group or channel", args[0])
+				}
+			})
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&rights, "rights", nil, "comma-separated admin rights, or all")
+	cmd.Flags().StringVar(&rank, "rank", "", "custom admin title")
+	return cmd
+}
+
+func newMemberDemoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "demote <peer> <user>",
+		Short: "Remove admin rights from a member",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMemberAction(cmd, args, func(ctx context.Context, api *tg.Client, chat peers.Peer, user peers.User) error {
+				switch p := chat.(type) {
+				case peers.Channel:
+					_, err := api.ChannelsEditAdmin(ctx, &tg.ChannelsEditAdminRequest{
+						Channel: p.InputChannel(),
+						UserID:  user.InputUser(),
+					})
+					return err
+				case peers.Chat:
+					_, err := api.MessagesEditChatAdmin(ctx, &tg.MessagesEditChatAdminRequest{
+						ChatID: p.ID(),
+						UserID: user.InputUser(),
+					})
+					return err
+				default:
+					return fmt.Errorf("%s is not a group or channel", args[0])
+				}
+			})
+		},
+	}
+	return cmd
+}
+
+func newChatPermissionsCmd() *cobra.Command {
+	var deny []string
+
+	cmd := &cobra.Command{
+		Use:   "permissions <peer>",
+		Short: "Show or set default member permissions",
+		Long: `Show or set the default permissions of a group.
+
+--deny sets the complete list of denied permissions; everything else is
+allowed. Use --deny= to allow everything.
+
+Permissions: ` + strings.Join(bannedRightNames(), ", ") + `.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			set := cmd.Flags().Changed("deny")
+			rights, err := parseBannedRights(deny)
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				if set {
+					if _, err := b.Client.API().MessagesEditChatDefaultBannedRights(ctx, &tg.MessagesEditChatDefaultBannedRightsRequest{
+						Peer:         peer.InputPeer(),
+						BannedRights: rights,
+					}); err != nil {
+						return err
+					}
+				} else {
+					switch p := peer.(type) {
+					case peers.Channel:
+						rights, _ = p.DefaultBannedRights()
+					case peers.Chat:
+						rights, _ = p.Raw().GetDefaultBannedRights()
+					default:
+						return fmt.Errorf("%s is not a group or channel", args[0])
+					}
+				}
+
+				denied := formatBannedRights(rights)
+				if denied == nil {
+					denied = []string{}
+				}
+				return rt.Printer.Render(types.PermissionsResult{
+					PeerRef: peerRefFromID(peer.TDLibPeerID()),
+					Title:   peer.VisibleName(),
+					Denied:  denied,
+				})
+			})
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&deny, "deny", nil, "comma-separated permissions to deny (replaces the current set)")
+	return cmd
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAdminRights(t *testing.T) {
+	rights, err := parseAdminRights([]string{"delete_messages", " ban_users", ""}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := formatAdminRights(rights), []string{"delete_messages", "ban_users"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("formatAdminRights = %v, want %v", got, want)
+	}
+
+	group, err := parseAdminRights([]string{"all"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if group.Anonymous || !group.AddAdmins || !group.PinMessages || !group.ManageTopics {
+		t.Fatalf("unexpected rights for all in a supergroup: %+v", group)
+	}
+	if group.PostMessages || group.EditMessages || group.PostStories || group.ManageDirectMessages {
+		t.Fatalf("all granted channel rights in a supergroup: %v", formatAdminRights(group))
+	}
+
+	channel, err := parseAdminRights([]string{"all"}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !channel.PostMessages || !channel.EditMessages || !channel.DeleteStories || !channel.ManageDirectMessages || !channel.AddAdmins {
+		t.Fatalf("unexpected rights for all in a channel: %+v", channel)
+	}
+	if channel.Anonymous || channel.PinMessages || channel.ManageTopics {
+		t.Fatalf("all granted group rights in a channel: %v", formatAdminRights(channel))
+	}
+
+	// Named rights are granted as asked, whatever the chat.
+	named, err := parseAdminRights([]string{"post_messages"}, false)
+	if err != nil || !named.PostMessages {
+		t.Fatalf("unexpected rights %+v, %v", named, err)
+	}
+
+	if _, err := parseAdminRights([]string{"delete_everything"}, false); err == nil {
+		t.Fatal("expected error for unknown right")
+	}
+}
+
+func TestParseBannedRights(t *testing.T) {
+	rights, err := parseBannedRights([]string{"send_media", "send_links"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rights.SendMedia || !rights.EmbedLinks {
+		t.Fatalf("unexpected rights: %+v", rights)
+	}
+
+	denied := formatBannedRights(rights)
+	if want := []string{"send_media", "embed_links"}; !reflect.DeepEqual(denied, want) {
+		t.Fatalf("formatBannedRights = %v, want %v", denied, want)
+	}
+	again, err := parseBannedRights(denied)
+	if err != nil || again != rights {
+		t.Fatalf("round trip = %+v, %v; want %+v", again, err, rights)
+	}
+
+	if _, err := parseBannedRights([]string{"send_everything"}); err == nil {
+		t.Fatal("expected error for unknown permission")
+	}
+}
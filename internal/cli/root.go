@@ -2,10 +2,10 @@ package cli
 
 import (
 	"errors"
-	"os"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/ghillb/tmgc/internal/config"
 	"github.com/ghillb/tmgc/internal/output"
@@ -32,6 +32,8 @@ func newRootCmd() *cobra.Command {
 		jsonOut    bool
 		plainOut   bool
 		noColor    bool
+		fields     []string
+		tmpl       string
 	)
 
 	cmd := &cobra.Command{
@@ -44,6 +46,9 @@ func newRootCmd() *cobra.Command {
 			if jsonOut && plainOut {
 				return errors.New("--json and --plain are mutually exclusive")
 			}
+			if len(fields) > 0 && tmpl != "" {
+				return errors.New("--fields and --template are mutually exclusive")
+			}
 
 			paths, err := config.ResolvePaths(configPath, profile)
 			if err != nil {
@@ -69,7 +74,9 @@ func newRootCmd() *cobra.Command {
 				mode = output.ModePlain
 			}
 
-			printer := output.NewPrinter(os.Stdout, os.Stderr, mode, noColor)
+			printer := output.NewPrinter(cmd.OutOrStdout(), cmd.ErrOrStderr(), mode, noColor)
+			printer.Fields = fields
+			printer.Template = tmpl
 			rt := &Runtime{
 				Paths:   paths,
 				Config:  &cfg,
@@ -89,18 +96,44 @@ func newRootCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(&jsonOut, "json", false, "JSON output")
 	cmd.PersistentFlags().BoolVar(&plainOut, "plain", false, "plain output")
 	cmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable color output")
+	cmd.PersistentFlags().StringSliceVar(&fields, "fields", nil, "comma-separated output fields (e.g. id,date,text)")
+	cmd.PersistentFlags().StringVar(&tmpl, "template", "", "Go template applied to each output item (e.g. '{{.PeerRef}} {{.Title}}')")
 
 	cmd.AddCommand(newAuthCmd())
+	cmd.AddCommand(newBatchCmd())
+	cmd.AddCommand(newBotCmd())
 	cmd.AddCommand(newChatCmd())
 	cmd.AddCommand(newContactCmd())
+	cmd.AddCommand(newCronCmd())
+	cmd.AddCommand(newFolderCmd())
+	cmd.AddCommand(newInviteCmd())
+	cmd.AddCommand(newMemberCmd())
 	cmd.AddCommand(newMessageCmd())
 	cmd.AddCommand(newSearchCmd())
+	cmd.AddCommand(newScheduleCmd())
+	cmd.AddCommand(newShellCmd())
+	cmd.AddCommand(newTopicCmd())
+	cmd.AddCommand(newTUICmd())
 
 	cmd.SetHelpTemplate(helpTemplate())
 
 	return cmd
 }
 
+// inheritedFlags returns the named global flags that were set on the current
+// invocation, formatted as arguments for a nested command run.
+func inheritedFlags(cmd *cobra.Command, names ...string) []string {
+	var args []string
+	cmd.Root().PersistentFlags().Visit(func(f *pflag.Flag) {
+		for _, name := range names {
+			if f.Name == name {
+				args = append(args, "--"+f.Name+"="+f.Value.String())
+			}
+		}
+	})
+	return args
+}
+
 func helpTemplate() string {
 	return `{{with (or .Long .Short)}}{{. | trimTrailingWhitespaces}}
 
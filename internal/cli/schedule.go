@@ -0,0 +1,270 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func newScheduleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Scheduled messages",
+	}
+
+	cmd.AddCommand(newScheduleListCmd())
+	cmd.AddCommand(newScheduleSendNowCmd())
+	cmd.AddCommand(newScheduleDeleteCmd())
+	cmd.AddCommand(newScheduleEditCmd())
+
+	return cmd
+}
+
+func newScheduleListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list <peer>",
+		Short: "List scheduled messages, soonest first",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				res, err := b.Client.API().MessagesGetScheduledHistory(ctx, &tg.MessagesGetScheduledHistoryRequest{
+					Peer: peer.InputPeer(),
+				})
+				if err != nil {
+					return err
+				}
+				messages, users, chats := extractMessages(res)
+				if err := b.Peers.Apply(ctx, users, chats); err != nil {
+					return err
+				}
+
+				items := buildMessageItems(messages, time.Time{})
+				slices.SortStableFunc(items, func(a, b types.MessageItem) int {
+					return a.Date.Compare(b.Date)
+				})
+				resolveSenders(ctx, b.Peers, items)
+				return printMessages(rt, items)
+			})
+		},
+	}
+	return cmd
+}
+
+func newScheduleSendNowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "send-now <peer> <id...>",
+		Short: "Send scheduled messages immediately",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScheduledAction(cmd, args, func(ctx context.Context, api *tg.Client, peer tg.InputPeerClass, ids []int) error {
+				_, err := api.MessagesSendScheduledMessages(ctx, &tg.MessagesSendScheduledMessagesRequest{Peer: peer, ID: ids})
+				return err
+			})
+		},
+	}
+	return cmd
+}
+
+func newScheduleDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <peer> <id...>",
+		Short: "Cancel scheduled messages",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScheduledAction(cmd, args, func(ctx context.Context, api *tg.Client, peer tg.InputPeerClass, ids []int) error {
+				_, err := api.MessagesDeleteScheduledMessages(ctx, &tg.MessagesDeleteScheduledMessagesRequest{Peer: peer, ID: ids})
+				return err
+			})
+		},
+	}
+	return cmd
+}
+
+func newScheduleEditCmd() *cobra.Command {
+	var (
+		at string
+		tz string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "edit <peer> <id>",
+		Short: "Move a scheduled message to another time",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			msgID, err := parseMessageID(args[1])
+			if err != nil {
+				return err
+			}
+			if at == "" {
+				return fmt.Errorf("--at is required")
+			}
+			date, err := scheduleTime(at, tz, time.Now())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				req := &tg.MessagesEditMessageRequest{Peer: peer.InputPeer(), ID: msgID}
+				req.SetScheduleDate(date)
+				if _, err := b.Client.API().MessagesEditMessage(ctx, req); err != nil {
+					return err
+				}
+				return rt.Printer.Render(types.SendResult{OK: true, MessageID: msgID})
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&at, "at", "", "new send time (same formats as message send --schedule)")
+	cmd.Flags().StringVar(&tz, "tz", "", "time zone for wall-clock times (IANA name, default local)")
+	return cmd
+}
+
+func runScheduledAction(cmd *cobra.Command, args []string, action func(ctx context.Context, api *tg.Client, peer tg.InputPeerClass, ids []int) error) error {
+	rt, err := runtimeFrom(cmd.Context())
+	if err != nil {
+		return err
+	}
+	ids := make([]int, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		id, err := parseMessageID(arg)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+	return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+		peer, err := resolvePeer(ctx, b.Peers, args[0])
+		if err != nil {
+			return err
+		}
+		if err := action(ctx, b.Client.API(), peer.InputPeer(), ids); err != nil {
+			return err
+		}
+		return rt.Printer.Render(types.SendResult{OK: true})
+	})
+}
+
+// scheduleTime parses a --schedule value in the given time zone and checks
+// that it lies in the future.
+func scheduleTime(value, tz string, now time.Time) (int, error) {
+	loc := time.Local
+	if tz != "" {
+		var err error
+		loc, err = time.LoadLocation(tz)
+		if err != nil {
+			return 0, fmt.Errorf("unknown time zone %q", tz)
+		}
+	}
+	date, err := parseSchedule(value, now, loc)
+	if err != nil {
+		return 0, err
+	}
+	if date <= int(now.Unix()) {
+		return 0, fmt.Errorf("schedule time must be in the future")
+	}
+	return date, nil
+}
+
+// parseSchedule reads unix seconds, RFC3339, a delay such as "+90m" or "+1d",
+// or a wall-clock time in loc: "18:30", "tomorrow 09:00" or
+// "2026-01-05 09:30". A bare clock time means its next occurrence.
+func parseSchedule(value string, now time.Time, loc *time.Location) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("schedule value is empty")
+	}
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if unix > 1_000_000_000_000 {
+			unix = unix / 1000
+		}
+		return int(unix), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return int(t.Unix()), nil
+	}
+	if delay, ok := strings.CutPrefix(value, "+"); ok {
+		t, err := parseUntil(delay, now)
+		if err != nil || delay == "" {
+			return 0, fmt.Errorf("invalid schedule delay %q (use e.g. +90m, +2h or +1d)", value)
+		}
+		return int(t.Unix()), nil
+	}
+	if t, ok := parseClock(value, now.In(loc)); ok {
+		return int(t.Unix()), nil
+	}
+	return 0, fmt.Errorf("invalid schedule time %q: use RFC3339, unix seconds, +2h, 18:30 or tomorrow 09:00", value)
+}
+
+// parseClock reads "[today|tomorrow|YYYY-MM-DD] HH:MM[:SS]" in now's location.
+func parseClock(value string, now time.Time) (time.Time, bool) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 || len(fields) > 2 {
+		return time.Time{}, false
+	}
+	day, clock := "", fields[len(fields)-1]
+	if len(fields) == 2 {
+		day = strings.ToLower(fields[0])
+	}
+
+	var hm time.Time
+	ok := false
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, clock); err == nil {
+			hm, ok = t, true
+			break
+		}
+	}
+	if !ok {
+		return time.Time{}, false
+	}
+
+	y, m, d := now.Date()
+	switch day {
+	case "", "today":
+	case "tomorrow":
+		d++
+	default:
+		date, err := time.Parse(time.DateOnly, day)
+		if err != nil {
+			return time.Time{}, false
+		}
+		y, m, d = date.Date()
+	}
+	t := time.Date(y, m, d, hm.Hour(), hm.Minute(), hm.Second(), 0, now.Location())
+	if day == "" && !t.After(now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, true
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	now := time.Date(2026, 1, 5, 20, 0, 0, 0, time.UTC)
+
+	t.Run("unix-seconds", func(t *testing.T) {
+		got, err := parseSchedule("1767595800", now, time.UTC)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != 1767595800 {
+			t.Fatalf("expected 1767595800, got %d", got)
+		}
+	})
+
+	t.Run("unix-millis", func(t *testing.T) {
+		got, err := parseSchedule("1767595800123", now, time.UTC)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != 1767595800 {
+			t.Fatalf("expected 1767595800, got %d", got)
+		}
+	})
+
+	t.Run("rfc3339", func(t *testing.T) {
+		ts := "2026-01-05T09:30:00Z"
+		got, err := parseSchedule(ts, now, time.UTC)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := int(time.Date(2026, 1, 5, 9, 30, 0, 0, time.UTC).Unix())
+		if got != want {
+			t.Fatalf("expected %d, got %d", want, got)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := parseSchedule("tomorrow", now, time.UTC); err == nil {
+			t.Fatalf("expected error for invalid schedule")
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		if _, err := parseSchedule(" ", now, time.UTC); err == nil {
+			t.Fatalf("expected error for empty schedule")
+		}
+	})
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tz database")
+	}
+	cases := []struct {
+		value string
+		loc   *time.Location
+		want  time.Time
+	}{
+		{"+2h", time.UTC, now.Add(2 * time.Hour)},
+		{"+1d", time.UTC, now.AddDate(0, 0, 1)},
+		{"21:30", time.UTC, time.Date(2026, 1, 5, 21, 30, 0, 0, time.UTC)},
+		{"09:00", time.UTC, time.Date(2026, 1, 6, 9, 0, 0, 0, time.UTC)},
+		{"today 22:00", time.UTC, time.Date(2026, 1, 5, 22, 0, 0, 0, time.UTC)},
+		{"Tomorrow 09:00", time.UTC, time.Date(2026, 1, 6, 9, 0, 0, 0, time.UTC)},
+		{"tomorrow 09:00", berlin, time.Date(2026, 1, 6, 8, 0, 0, 0, time.UTC)},
+		{"2026-02-01 07:15:30", berlin, time.Date(2026, 2, 1, 6, 15, 30, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		got, err := parseSchedule(c.value, now, c.loc)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", c.value, err)
+		}
+		if got != int(c.want.Unix()) {
+			t.Fatalf("%q: expected %v, got %v", c.value, c.want, time.Unix(int64(got), 0).UTC())
+		}
+	}
+
+	for _, bad := range []string{"+", "+soon", "25:00", "next week 09:00", "yesterday 09:00"} {
+		if _, err := parseSchedule(bad, now, time.UTC); err == nil {
+			t.Fatalf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestScheduleTime(t *testing.T) {
+	now := time.Date(2026, 1, 5, 20, 0, 0, 0, time.UTC)
+	if _, err := scheduleTime("2026-01-05T19:00:00Z", "", now); err == nil {
+		t.Fatalf("expected error for past time")
+	}
+	if _, err := scheduleTime("+1h", "Mars/Olympus", now); err == nil {
+		t.Fatalf("expected error for unknown zone")
+	}
+	if _, err := scheduleTime("+1h", "UTC", now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -3,7 +3,6 @@ package cli
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/gotd/td/tg"
@@ -26,6 +25,7 @@ func newSearchMessagesCmd() *cobra.Command {
 	var (
 		peerRef string
 		limit   int
+		topicID int
 	)
 
 	cmd := &cobra.Command{
@@ -37,6 +37,9 @@ func newSearchMessagesCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if topicID != 0 && peerRef == "" {
+				return fmt.Errorf("--topic requires --chat")
+			}
 			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
 			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
 				query := args[0]
@@ -48,14 +51,15 @@ func newSearchMessagesCmd() *cobra.Command {
 						Limit:      limit,
 					})
 				} else {
-					peer, err := resolvePeer(ctx, b.Peers, peerRef)
-					if err != nil {
-						return err
+					peer, perr := resolvePeer(ctx, b.Peers, peerRef)
+					if perr != nil {
+						return perr
 					}
 					res, err = b.Client.API().MessagesSearch(ctx, &tg.MessagesSearchRequest{
-						Peer:  peer.InputPeer(),
-						Q:     query,
-						Limit: limit,
+						Peer:     peer.InputPeer(),
+						Q:        query,
+						Limit:    limit,
+						TopMsgID: topicID,
 					})
 				}
 				if err != nil {
@@ -68,39 +72,15 @@ func newSearchMessagesCmd() *cobra.Command {
 				}
 
 				items := buildMessageItems(messages, time.Time{})
-				switch rt.Printer.Mode {
-				case "json":
-					return rt.Printer.JSON(items)
-				case "plain":
-					lines := make([]string, 0, len(items))
-					for _, item := range items {
-						line := fmt.Sprintf("%d\t%s\t%d\t%s",
-							item.ID,
-							item.Date.Format(time.RFC3339),
-							item.FromPeerID,
-							item.Text,
-						)
-						lines = append(lines, line)
-					}
-					rt.Printer.Plain(lines)
-				default:
-					rows := [][]string{{"ID", "DATE", "FROM", "TEXT"}}
-					for _, item := range items {
-						rows = append(rows, []string{
-							strconv.Itoa(item.ID),
-							item.Date.Format(time.RFC3339),
-							strconv.FormatInt(item.FromPeerID, 10),
-							item.Text,
-						})
-					}
-					rt.Printer.Table(rows)
-				}
-				return nil
+				resolveSenders(ctx, b.Peers, items)
+				return printMessages(rt, items)
 			})
 		},
 	}
 
 	cmd.Flags().StringVar(&peerRef, "chat", "", "chat peer (u123, c123, ch123, @username, or phone)")
 	cmd.Flags().IntVar(&limit, "limit", 20, "limit number of results")
+	cmd.Flags().IntVar(&topicID, "topic", 0, "only messages in this forum topic (requires --chat)")
+	markPeerFlags(cmd.Flags(), "chat")
 	return cmd
 }
@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/telegram/peers"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+// slowModeSteps are the slow mode delays Telegram accepts, in seconds.
+var slowModeSteps = []int{0, 10, 30, 60, 300, 900, 3600}
+
+func newChatEditCmd() *cobra.Command {
+	var (
+		title          string
+		about          string
+		photo          string
+		slowMode       string
+		historyVisible bool
+		signatures     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "edit <peer>",
+		Short: "Change chat title, description, photo and settings",
+		Long: `Change chat title, description, photo and settings. Only the given flags
+are applied; values that are already set are not an error.
+
+--slow-mode, --history-visible and --signatures need a supergroup or channel.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			flags := cmd.Flags()
+			changed := false
+			for _, name := range []string{"title", "about", "photo", "slow-mode", "history-visible", "signatures"} {
+				changed = changed || flags.Changed(name)
+			}
+			if !changed {
+				return fmt.Errorf("nothing to change: use --title, --about, --photo, --slow-mode, --history-visible or --signatures")
+			}
+			slowSeconds := 0
+			if flags.Changed("slow-mode") {
+				slowSeconds, err = parseSlowMode(slowMode)
+				if err != nil {
+					return err
+				}
+			}
+			if flags.Changed("title") && strings.TrimSpace(title) == "" {
+				return fmt.Errorf("--title cannot be empty")
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+				api := b.Client.API()
+
+				var (
+					chat    peers.Chat
+					channel peers.Channel
+					isChat  bool
+				)
+				switch p := peer.(type) {
+				case peers.Chat:
+					chat, isChat = p, true
+				case peers.Channel:
+					channel = p
+				default:
+					return fmt.Errorf("%s is not a group or channel", args[0])
+				}
+				needChannel := func(flag string) error {
+					if isChat {
+						return fmt.Errorf("%s requires a supergroup or channel", flag)
+					}
+					return nil
+				}
+
+				var steps []func() error
+				if flags.Changed("title") {
+					steps = append(steps, func() error {
+						if isChat {
+							return chat.SetTitle(ctx, title)
+						}
+						return channel.SetTitle(ctx, title)
+					})
+				}
+				if flags.Changed("about") {
+					steps = append(steps, func() error {
+						_, err := api.MessagesEditChatAbout(ctx, &tg.MessagesEditChatAboutRequest{
+							Peer:  peer.InputPeer(),
+							About: about,
+						})
+						return err
+					})
+				}
+				if flags.Changed("photo") {
+					steps = append(steps, func() error {
+						input, err := uploadChatPhoto(ctx, api, photo)
+						if err != nil {
+							return err
+						}
+						if isChat {
+							_, err = api.MessagesEditChatPhoto(ctx, &tg.MessagesEditChatPhotoRequest{ChatID: chat.ID(), Photo: input})
+						} else {
+							_, err = api.ChannelsEditPhoto(ctx, &tg.ChannelsEditPhotoRequest{Channel: channel.InputChannel(), Photo: input})
+						}
+						return err
+					})
+				}
+				if flags.Changed("slow-mode") {
+					if err := needChannel("--slow-mode"); err != nil {
+						return err
+					}
+					steps = append(steps, func() error {
+						_, err := api.ChannelsToggleSlowMode(ctx, &tg.ChannelsToggleSlowModeRequest{
+							Channel: channel.InputChannel(),
+							Seconds: slowSeconds,
+						})
+						return err
+					})
+				}
+				if flags.Changed("history-visible") {
+					if err := needChannel("--history-visible"); err != nil {
+						return err
+					}
+					steps = append(steps, func() error {
+						_, err := api.ChannelsTogglePreHistoryHidden(ctx, &tg.ChannelsTogglePreHistoryHiddenRequest{
+							Channel: channel.InputChannel(),
+							Enabled: !historyVisible,
+						})
+						return err
+					})
+				}
+				if flags.Changed("signatures") {
+					if err := needChannel("--signatures"); err != nil {
+						return err
+					}
+					steps = append(steps, func() error {
+						_, err := api.ChannelsToggleSignatures(ctx, &tg.ChannelsToggleSignaturesRequest{
+							Channel:           channel.InputChannel(),
+							SignaturesEnabled: signatures,
+						})
+						return err
+					})
+				}
+
+				for _, step := range steps {
+					if err := step(); err != nil && !tgerr.Is(err, "CHAT_NOT_MODIFIED", "CHAT_ABOUT_NOT_MODIFIED") {
+						return err
+					}
+				}
+				return rt.Printer.Render(types.SendResult{OK: true})
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&title, "title", "", "new title")
+	cmd.Flags().StringVar(&about, "about", "", "new description (empty clears it)")
+	cmd.Flags().StringVar(&photo, "photo", "", "image file to use as chat photo")
+	cmd.Flags().StringVar(&slowMode, "slow-mode", "", "delay between messages per member: off, 10s, 30s, 1m, 5m, 15m or 1h")
+	cmd.Flags().BoolVar(&historyVisible, "history-visible", false, "show chat history to new members")
+	cmd.Flags().BoolVar(&signatures, "signatures", false, "sign channel posts with the author's name")
+	return cmd
+}
+
+func newChatMuteCmd() *cobra.Command {
+	var (
+		duration string
+		forever  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "mute <peer>",
+		Short: "Mute notifications for a chat",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if duration != "" && forever {
+				return fmt.Errorf("use --for or --forever, not both")
+			}
+			until := time.Unix(math.MaxInt32, 0)
+			if duration != "" {
+				var err error
+				until, err = muteUntil(duration, time.Now())
+				if err != nil {
+					return err
+				}
+			}
+			return runNotifySettings(cmd, args[0], int(until.Unix()))
+		},
+	}
+
+	cmd.Flags().StringVar(&duration, "for", "", "mute duration (e.g. 8h, 7d) or RFC3339 end time")
+	cmd.Flags().BoolVar(&forever, "forever", false, "mute until unmuted (default)")
+	return cmd
+}
+
+// muteUntil reads --for; an end time that has passed would unmute the chat.
+func muteUntil(value string, now time.Time) (time.Time, error) {
+	return parseFutureUntil(value, now, 0, "mute end time")
+}
+
+func newChatUnmuteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unmute <peer>",
+		Short: "Unmute notifications for a chat",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runNotifySettings(cmd, args[0], 0)
+		},
+	}
+	return cmd
+}
+
+func runNotifySettings(cmd *cobra.Command, input string, muteUntil int) error {
+	rt, err := runtimeFrom(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+	return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+		peer, err := resolvePeer(ctx, b.Peers, input)
+		if err != nil {
+			return err
+		}
+
+		var settings tg.InputPeerNotifySettings
+		// Set explicitly so that 0 (unmute) is sent rather than omitted.
+		settings.SetMuteUntil(muteUntil)
+		if _, err := b.Client.API().AccountUpdateNotifySettings(ctx, &tg.AccountUpdateNotifySettingsRequest{
+			Peer:     &tg.InputNotifyPeer{Peer: peer.InputPeer()},
+			Settings: settings,
+		}); err != nil {
+			return err
+		}
+		return rt.Printer.Render(types.SendResult{OK: true})
+	})
+}
+
+// uploadChatPhoto uploads an image through uploadMedia for use as a chat photo.
+func uploadChatPhoto(ctx context.Context, api *tg.Client, path string) (tg.InputChatPhotoClass, error) {
+	media, err := uploadMedia(ctx, api, path, uploadOptions{})
+	if err != nil {
+		return nil, err
+	}
+	photo, ok := media.(*tg.InputMediaUploadedPhoto)
+	if !ok {
+		return nil, fmt.Errorf("chat photo must be an image: %s", path)
+	}
+	return &tg.InputChatUploadedPhoto{File: photo.File}, nil
+}
+
+// parseSlowMode reads "off" or a duration matching one of Telegram's slow mode steps.
+func parseSlowMode(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "off" || value == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err == nil {
+		for _, step := range slowModeSteps {
+			if d == time.Duration(step)*time.Second {
+				return step, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid --slow-mode %q (use off, 10s, 30s, 1m, 5m, 15m or 1h)", value)
+}
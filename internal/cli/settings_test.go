@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSlowMode(t *testing.T) {
+	cases := map[string]int{
+		"off": 0,
+		"0":   0,
+		"10s": 10,
+		"1m":  60,
+		"15m": 900,
+		"1h":  3600,
+	}
+	for in, want := range cases {
+		got, err := parseSlowMode(in)
+		if err != nil || got != want {
+			t.Fatalf("parseSlowMode(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "20s", "2h", "fast"} {
+		if _, err := parseSlowMode(in); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
+
+func TestMuteUntil(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	until, err := muteUntil("8h", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := now.Add(8 * time.Hour); !until.Equal(want) {
+		t.Fatalf("muteUntil(8h) = %v, want %v", until, want)
+	}
+	if _, err := muteUntil("2026-01-06T09:00:00Z", now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, value := range []string{"2026-01-05T11:00:00Z", "2026-01-05T12:00:00Z"} {
+		if _, err := muteUntil(value, now); err == nil {
+			t.Fatalf("expected error for %s", value)
+		}
+	}
+}
@@ -0,0 +1,505 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+)
+
+const shellHistoryLimit = 500
+
+// shellGlobalFlags are inherited from the `tmgc shell` invocation by every command.
+var shellGlobalFlags = []string{"config", "profile", "timeout", "no-color", "json", "plain"}
+
+func newShellCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "shell",
+		Short: "Interactive shell on a persistent connection",
+		Long: `Interactive shell on a persistent connection.
+
+Accepts the same commands as tmgc (e.g. "chat list", "message send @x hi").
+Built-ins: use <peer>, send <text>, history [flags], help, exit.
+After "use <peer>", "." stands for the current chat wherever a peer is expected.`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{longRunningAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			globals := inheritedFlags(cmd, shellGlobalFlags...)
+
+			// The connection lives for the whole session; --timeout applies per command.
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, 0)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				sh := &shell{rt: rt, bundle: b, globals: globals}
+				return sh.run(ctx)
+			})
+		},
+	}
+	return cmd
+}
+
+type shell struct {
+	rt      *Runtime
+	bundle  *tgclient.Bundle
+	globals []string
+
+	peerRef  string
+	peerName string
+}
+
+func (s *shell) run(ctx context.Context) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if done := s.exec(ctx, scanner.Text()); done {
+				return nil
+			}
+		}
+		return scanner.Err()
+	}
+
+	history, err := loadShellHistory(s.rt.Paths.HistoryPath, shellHistoryLimit)
+	if err != nil {
+		return err
+	}
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "")
+	t.History = history
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		newLine, newPos, candidates := s.complete(line, pos)
+		if len(candidates) > 1 {
+			fmt.Fprintln(t, strings.Join(candidates, "  "))
+		}
+		return newLine, newPos, true
+	}
+
+	for {
+		t.SetPrompt(s.prompt())
+		line, err := readShellLine(fd, t)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if done := s.exec(ctx, line); done {
+			return nil
+		}
+	}
+}
+
+// readShellLine reads one line in raw mode and restores the terminal so that
+// command output is rendered normally.
+func readShellLine(fd int, t *term.Terminal) (string, error) {
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(fd, state)
+	return t.ReadLine()
+}
+
+func (s *shell) prompt() string {
+	if s.peerName != "" {
+		return fmt.Sprintf("tmgc(%s)> ", s.peerName)
+	}
+	return "tmgc> "
+}
+
+// exec runs a single shell line and reports whether the shell should exit.
+func (s *shell) exec(ctx context.Context, line string) bool {
+	args, err := splitShellArgs(line)
+	if err != nil {
+		s.rt.Printer.Logln(err)
+		return false
+	}
+	if len(args) == 0 || strings.HasPrefix(args[0], "#") {
+		return false
+	}
+
+	switch args[0] {
+	case "exit", "quit":
+		return true
+	case "help":
+		s.rt.Printer.Logln("Commands: any tmgc command, e.g. `chat list` or `message send @x hi`.")
+		s.rt.Printer.Logln("Built-ins: use <peer>, use -, send <text>, history [flags], help, exit.")
+		s.rt.Printer.Logln("After `use <peer>`, \".\" stands for the current chat wherever a peer is expected.")
+		return false
+	case "shell":
+		s.rt.Printer.Logln("already in a shell")
+		return false
+	case "use":
+		if err := s.use(ctx, args[1:]); err != nil {
+			s.rt.Printer.Logln(err)
+		}
+		return false
+	}
+
+	args, err = s.expand(args)
+	if err != nil {
+		s.rt.Printer.Logln(err)
+		return false
+	}
+
+	cmdCtx, stop := signal.NotifyContext(tgclient.WithBundle(ctx, s.bundle), os.Interrupt)
+	defer stop()
+
+	root := newRootCmd()
+	root.SetArgs(append(append([]string{}, s.globals...), args...))
+	if err := root.ExecuteContext(cmdCtx); err != nil {
+		s.rt.Printer.Logln(err)
+	}
+	return false
+}
+
+func (s *shell) use(ctx context.Context, args []string) error {
+	switch {
+	case len(args) == 0:
+		if s.peerRef == "" {
+			return errors.New("no chat in use")
+		}
+		s.rt.Printer.Logf("%s (%s)\n", s.peerName, s.peerRef)
+		return nil
+	case len(args) > 1:
+		return errors.New("usage: use <peer>")
+	case args[0] == "-":
+		s.peerRef, s.peerName = "", ""
+		return nil
+	}
+
+	// Like every other command, a zero --timeout means no timeout.
+	if s.rt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.rt.Timeout)
+		defer cancel()
+	}
+	peer, err := resolvePeer(ctx, s.bundle.Peers, args[0])
+	if err != nil {
+		return err
+	}
+	s.peerRef = peerRefFromID(peer.TDLibPeerID())
+	s.peerName = peer.VisibleName()
+	return nil
+}
+
+// expand rewrites shell shortcuts into regular commands and substitutes "."
+// with the chat selected by `use`. Only arguments that name a peer are
+// replaced: <peer> positionals and flags marked with peerFlagAnnotation, so
+// `send .` still sends a dot.
+func (s *shell) expand(args []string) ([]string, error) {
+	switch args[0] {
+	case "send":
+		args = append([]string{"message", "send", "."}, args[1:]...)
+	case "history":
+		args = append([]string{"chat", "history", "."}, args[1:]...)
+	}
+
+	cmd := newRootCmd()
+	i := 0
+	for ; i < len(args); i++ {
+		sub := findSubcommand(cmd, args[i])
+		if sub == nil {
+			break
+		}
+		cmd = sub
+	}
+
+	out := append([]string{}, args...)
+	positionals := usePositionals(cmd.Use)
+	pos := 0
+	flagsDone := false
+	for ; i < len(out); i++ {
+		arg := out[i]
+		if !flagsDone && arg == "--" {
+			flagsDone = true
+			continue
+		}
+		if flagsDone || !strings.HasPrefix(arg, "-") || arg == "-" {
+			if peerPositional(positionals, pos) && arg == "." {
+				if s.peerRef == "" {
+					return nil, errNoShellPeer
+				}
+				out[i] = s.peerRef
+			}
+			pos++
+			continue
+		}
+
+		flag, value, inline := lookupShellFlag(cmd, arg)
+		switch {
+		case flag == nil || flag.NoOptDefVal != "":
+		case inline:
+			if value == "." && isPeerFlag(flag) {
+				if s.peerRef == "" {
+					return nil, errNoShellPeer
+				}
+				out[i] = strings.TrimSuffix(arg, ".") + s.peerRef
+			}
+		case i+1 < len(out):
+			i++
+			if out[i] == "." && isPeerFlag(flag) {
+				if s.peerRef == "" {
+					return nil, errNoShellPeer
+				}
+				out[i] = s.peerRef
+			}
+		}
+	}
+	return out, nil
+}
+
+var errNoShellPeer = errors.New("no chat in use: run `use <peer>` first")
+
+// peerPositional reports whether positional argument pos names a peer.
+func peerPositional(positionals []batchPositional, pos int) bool {
+	if len(positionals) == 0 {
+		return false
+	}
+	if pos >= len(positionals) {
+		last := positionals[len(positionals)-1]
+		return last.variadic && last.name == "peer"
+	}
+	return positionals[pos].name == "peer"
+}
+
+// lookupShellFlag finds the flag named by arg ("--chat", "--chat=x" or "-n")
+// and returns its inline value, if any.
+func lookupShellFlag(cmd *cobra.Command, arg string) (*pflag.Flag, string, bool) {
+	name, value, inline := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+	flag := cmd.Flags().Lookup(name)
+	if flag == nil {
+		flag = cmd.InheritedFlags().Lookup(name)
+	}
+	if flag == nil && !strings.HasPrefix(arg, "--") && len(name) == 1 {
+		flag = cmd.Flags().ShorthandLookup(name)
+	}
+	return flag, value, inline
+}
+
+func (s *shell) complete(line string, pos int) (string, int, []string) {
+	head := line[:pos]
+	start := strings.LastIndexAny(head, " \t") + 1
+	word := head[start:]
+	words := strings.Fields(head[:start])
+
+	var options []string
+	switch {
+	case len(words) == 0:
+		options = append(commandNames(newRootCmd()), "use", "send", "history", "help", "exit")
+	case len(words) == 1:
+		if sub := findSubcommand(newRootCmd(), words[0]); sub != nil && sub.HasSubCommands() {
+			options = commandNames(sub)
+		}
+	}
+	if options == nil && !strings.HasPrefix(word, "-") {
+		options = s.bundle.PeerStore.PeerRefs()
+	}
+
+	candidates := completeCandidates(options, word)
+	if len(candidates) == 0 {
+		return line, pos, nil
+	}
+	replacement := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		replacement += " "
+	}
+	newLine := line[:start] + replacement + line[pos:]
+	return newLine, start + len(replacement), candidates
+}
+
+func commandNames(cmd *cobra.Command) []string {
+	names := make([]string, 0, len(cmd.Commands()))
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() {
+			names = append(names, sub.Name())
+		}
+	}
+	return names
+}
+
+func findSubcommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, sub := range cmd.Commands() {
+		if sub.Name() == name || sub.HasAlias(name) {
+			return sub
+		}
+	}
+	return nil
+}
+
+func completeCandidates(options []string, prefix string) []string {
+	var out []string
+	seen := make(map[string]struct{}, len(options))
+	for _, opt := range options {
+		if _, ok := seen[opt]; ok || !strings.HasPrefix(opt, prefix) {
+			continue
+		}
+		seen[opt] = struct{}{}
+		out = append(out, opt)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func commonPrefix(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	prefix := values[0]
+	for _, v := range values[1:] {
+		for !strings.HasPrefix(v, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// splitShellArgs splits a line into arguments, honoring single quotes,
+// double quotes and backslash escapes.
+func splitShellArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		cur     strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inWord {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// shellHistory is a term.History backed by a per-profile file. Entries are
+// appended to the file, which is rewritten with the last limit entries once
+// it holds twice as many.
+type shellHistory struct {
+	path      string
+	limit     int
+	entries   []string
+	fileLines int
+}
+
+func loadShellHistory(path string, limit int) (*shellHistory, error) {
+	h := &shellHistory{path: path, limit: limit}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, fmt.Errorf("read shell history: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	h.fileLines = len(h.entries)
+	if len(h.entries) > limit {
+		h.entries = h.entries[len(h.entries)-limit:]
+	}
+	return h, nil
+}
+
+func (h *shellHistory) Add(entry string) {
+	if strings.TrimSpace(entry) == "" {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == entry {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > h.limit {
+		h.entries = h.entries[len(h.entries)-h.limit:]
+	}
+
+	if h.fileLines+1 > 2*h.limit {
+		if err := h.rewrite(); err == nil {
+			return
+		}
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintln(f, entry); err == nil {
+		h.fileLines++
+	}
+}
+
+// rewrite replaces the history file with the in-memory entries.
+func (h *shellHistory) rewrite() error {
+	data := strings.Join(h.entries, "\n") + "\n"
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(data), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		return err
+	}
+	h.fileLines = len(h.entries)
+	return nil
+}
+
+func (h *shellHistory) Len() int {
+	return len(h.entries)
+}
+
+func (h *shellHistory) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitShellArgs(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "chat list --limit 5", want: []string{"chat", "list", "--limit", "5"}},
+		{in: `message send @x "hello world"`, want: []string{"message", "send", "@x", "hello world"}},
+		{in: `send 'it''s' ok`, want: []string{"send", "its", "ok"}},
+		{in: `send a\ b`, want: []string{"send", "a b"}},
+		{in: `send ""`, want: []string{"send", ""}},
+		{in: "   ", want: nil},
+		{in: `send "open`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := splitShellArgs(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("splitShellArgs(%q) expected error", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("splitShellArgs(%q) unexpected error: %v", tt.in, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("splitShellArgs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestShellExpand(t *testing.T) {
+	sh := &shell{}
+	if _, err := sh.expand([]string{"send", "hi"}); err == nil {
+		t.Fatalf("expected error without a chat in use")
+	}
+
+	sh.peerRef = "u42"
+	tests := []struct {
+		in   []string
+		want []string
+	}{
+		{in: []string{"send", "hi"}, want: []string{"message", "send", "u42", "hi"}},
+		{in: []string{"history", "--limit", "5"}, want: []string{"chat", "history", "u42", "--limit", "5"}},
+		{in: []string{"search", "messages", "x", "--chat", "."}, want: []string{"search", "messages", "x", "--chat", "u42"}},
+		{in: []string{"chat", "list"}, want: []string{"chat", "list"}},
+		{in: []string{"send", "."}, want: []string{"message", "send", "u42", "."}},
+		{in: []string{"message", "send", ".", "."}, want: []string{"message", "send", "u42", "."}},
+		{in: []string{"search", "messages", ".", "--chat=."}, want: []string{"search", "messages", ".", "--chat=u42"}},
+		{in: []string{"member", "kick", ".", "."}, want: []string{"member", "kick", "u42", "."}},
+		{in: []string{"chat", "archive", "@a", "."}, want: []string{"chat", "archive", "@a", "u42"}},
+		{in: []string{"folder", "edit", "1", "--remove", "."}, want: []string{"folder", "edit", "1", "--remove", "u42"}},
+	}
+	for _, tt := range tests {
+		got, err := sh.expand(tt.in)
+		if err != nil {
+			t.Fatalf("expand(%q) unexpected error: %v", tt.in, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("expand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompleteCandidates(t *testing.T) {
+	got := completeCandidates([]string{"chat", "contact", "auth", "chat"}, "c")
+	if !reflect.DeepEqual(got, []string{"chat", "contact"}) {
+		t.Fatalf("completeCandidates() = %q", got)
+	}
+	if p := commonPrefix([]string{"ch123", "ch129"}); p != "ch12" {
+		t.Fatalf("commonPrefix() = %q, want ch12", p)
+	}
+}
+
+func TestShellHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shell_history")
+	h, err := loadShellHistory(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Add("chat list")
+	h.Add("chat list")
+	h.Add("use @x")
+	h.Add("send hi")
+
+	reloaded, err := loadShellHistory(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Len() != 2 || reloaded.At(0) != "send hi" || reloaded.At(1) != "use @x" {
+		t.Fatalf("unexpected history: %q", reloaded.entries)
+	}
+	for i := 0; i < 10; i++ {
+		reloaded.Add(fmt.Sprintf("send %d", i))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines > 4 {
+		t.Fatalf("history file has %d lines, want at most 4:\n%s", lines, data)
+	}
+	reloaded, err = loadShellHistory(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Len() != 2 || reloaded.At(0) != "send 9" || reloaded.At(1) != "send 8" {
+		t.Fatalf("unexpected history: %q", reloaded.entries)
+	}
+}
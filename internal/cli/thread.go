@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/telegram/peers"
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+)
+
+func newMessageRepliesCmd() *cobra.Command {
+	var (
+		limit int
+		since string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replies <peer> <id>",
+		Short: "Read the reply thread of a message (or comments on a channel post)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			msgID, err := parseMessageID(args[1])
+			if err != nil {
+				return err
+			}
+			cutoff, err := parseSince(since)
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				peer, err := resolvePeer(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				items, err := loadHistory(ctx, b, peer.InputPeer(), msgID, limit, cutoff)
+				if err != nil {
+					return err
+				}
+				return printMessages(rt, items)
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 20, "limit number of messages")
+	cmd.Flags().StringVar(&since, "since", "", "filter messages after RFC3339 timestamp")
+	return cmd
+}
+
+// discussionThread returns the linked discussion group of a channel post and
+// the ID of the message there that comments reply to.
+func discussionThread(ctx context.Context, b *tgclient.Bundle, channel peers.Peer, postID int) (peers.Peer, int, error) {
+	res, err := b.Client.API().MessagesGetDiscussionMessage(ctx, &tg.MessagesGetDiscussionMessageRequest{
+		Peer:  channel.InputPeer(),
+		MsgID: postID,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("post %d has no comment thread: %w", postID, err)
+	}
+	if err := b.Peers.Apply(ctx, res.Users, res.Chats); err != nil {
+		return nil, 0, err
+	}
+
+	// Albums return several messages, newest first; the thread starts at the oldest.
+	if len(res.Messages) == 0 {
+		return nil, 0, fmt.Errorf("post %d has no comment thread", postID)
+	}
+	top, ok := res.Messages[len(res.Messages)-1].(*tg.Message)
+	if !ok {
+		return nil, 0, fmt.Errorf("post %d has no comment thread", postID)
+	}
+	group, err := b.Peers.ResolvePeer(ctx, top.PeerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return group, top.ID, nil
+}
@@ -0,0 +1,345 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/gotd/td/telegram/peers"
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func newTopicCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "topic",
+		Short: "Forum topics",
+	}
+
+	cmd.AddCommand(newTopicListCmd())
+	cmd.AddCommand(newTopicCreateCmd())
+	cmd.AddCommand(newTopicEditCmd())
+	cmd.AddCommand(newTopicCloseCmd("close", "Close a topic to new messages", true))
+	cmd.AddCommand(newTopicCloseCmd("reopen", "Reopen a closed topic", false))
+	cmd.AddCommand(newTopicDeleteCmd())
+
+	return cmd
+}
+
+func newTopicListCmd() *cobra.Command {
+	var (
+		query string
+		limit int
+		all   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list <peer>",
+		Short: "List forum topics",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if all {
+				limit = 0
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				forum, err := resolveForum(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				req := &tg.MessagesGetForumTopicsRequest{Peer: forum.InputPeer(), Q: query}
+				items := make([]types.TopicItem, 0)
+				seen := make(map[int]bool)
+				for limit == 0 || len(items) < limit {
+					req.Limit = 100
+					if limit > 0 {
+						req.Limit = min(limit-len(items), 100)
+					}
+					res, err := b.Client.API().MessagesGetForumTopics(ctx, req)
+					if err != nil {
+						return err
+					}
+
+					dates := make(map[int]int, len(res.Messages))
+					for _, m := range res.Messages {
+						if msg, ok := m.(interface{ GetDate() int }); ok {
+							dates[m.GetID()] = msg.GetDate()
+						}
+					}
+
+					var last *tg.ForumTopic
+					for _, t := range res.Topics {
+						topic, ok := t.(*tg.ForumTopic)
+						if !ok || seen[topic.ID] {
+							continue
+						}
+						seen[topic.ID] = true
+						items = append(items, topicItem(topic))
+						last = topic
+					}
+					if last == nil || len(items) >= res.Count {
+						break
+					}
+					req.OffsetDate, req.OffsetID, req.OffsetTopic = dates[last.TopMessage], last.TopMessage, last.ID
+				}
+				return rt.Printer.Render(items)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&query, "query", "", "only topics whose title matches")
+	cmd.Flags().IntVar(&limit, "limit", 100, "limit number of topics")
+	cmd.Flags().BoolVar(&all, "all", false, "list every topic (ignores --limit)")
+	return cmd
+}
+
+func newTopicCreateCmd() *cobra.Command {
+	var (
+		iconColor int
+		iconEmoji int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create <peer> <title>",
+		Short: "Create a forum topic",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				forum, err := resolveForum(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				updates, err := b.Client.API().MessagesCreateForumTopic(ctx, &tg.MessagesCreateForumTopicRequest{
+					Peer:        forum.InputPeer(),
+					Title:       args[1],
+					IconColor:   iconColor,
+					IconEmojiID: iconEmoji,
+					RandomID:    rand.Int63(),
+				})
+				if err != nil {
+					return err
+				}
+				id, ok := createdTopicID(updates)
+				if !ok {
+					return fmt.Errorf("no topic in %T response", updates)
+				}
+				return rt.Printer.Render(types.TopicItem{ID: id, Title: args[1], IconEmojiID: iconEmoji})
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&iconColor, "icon-color", 0, "icon color as RGB integer (Telegram accepts a fixed palette)")
+	cmd.Flags().Int64Var(&iconEmoji, "icon-emoji", 0, "custom emoji document ID to use as icon")
+	return cmd
+}
+
+func newTopicEditCmd() *cobra.Command {
+	var (
+		title     string
+		iconEmoji int64
+		hidden    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "edit <peer> <topic>",
+		Short: "Rename a topic or change its icon",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+			if !flags.Changed("title") && !flags.Changed("icon-emoji") && !flags.Changed("hidden") {
+				return fmt.Errorf("nothing to change: use --title, --icon-emoji or --hidden")
+			}
+			return runTopicEdit(cmd, args, func(req *tg.MessagesEditForumTopicRequest) {
+				if flags.Changed("title") {
+					req.SetTitle(title)
+				}
+				if flags.Changed("icon-emoji") {
+					req.SetIconEmojiID(iconEmoji)
+				}
+				if flags.Changed("hidden") {
+					req.SetHidden(hidden)
+				}
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&title, "title", "", "new title")
+	cmd.Flags().Int64Var(&iconEmoji, "icon-emoji", 0, "custom emoji document ID (0 removes the icon)")
+	cmd.Flags().BoolVar(&hidden, "hidden", false, "hide the General topic (topic 1 only)")
+	return cmd
+}
+
+func newTopicCloseCmd(use, short string, closed bool) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use + " <peer> <topic>",
+		Short: short,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTopicEdit(cmd, args, func(req *tg.MessagesEditForumTopicRequest) {
+				req.SetClosed(closed)
+			})
+		},
+	}
+	return cmd
+}
+
+func newTopicDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <peer> <topic>",
+		Short: "Delete a topic and all its messages",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+			topicID, err := parseMessageID(args[1])
+			if err != nil {
+				return err
+			}
+
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				forum, err := resolveForum(ctx, b.Peers, args[0])
+				if err != nil {
+					return err
+				}
+
+				if err := drainAffected(func() (*tg.MessagesAffectedHistory, error) {
+					return b.Client.API().MessagesDeleteTopicHistory(ctx, &tg.MessagesDeleteTopicHistoryRequest{
+						Peer:     forum.InputPeer(),
+						TopMsgID: topicID,
+					})
+				}); err != nil {
+					return err
+				}
+				return rt.Printer.Render(types.SendResult{OK: true})
+			})
+		},
+	}
+	return cmd
+}
+
+func runTopicEdit(cmd *cobra.Command, args []string, edit func(req *tg.MessagesEditForumTopicRequest)) error {
+	rt, err := runtimeFrom(cmd.Context())
+	if err != nil {
+		return err
+	}
+	topicID, err := parseMessageID(args[1])
+	if err != nil {
+		return err
+	}
+
+	factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, rt.Timeout)
+	return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+		forum, err := resolveForum(ctx, b.Peers, args[0])
+		if err != nil {
+			return err
+		}
+
+		req := &tg.MessagesEditForumTopicRequest{Peer: forum.InputPeer(), TopicID: topicID}
+		edit(req)
+		if _, err := b.Client.API().MessagesEditForumTopic(ctx, req); err != nil {
+			return err
+		}
+		return rt.Printer.Render(types.SendResult{OK: true})
+	})
+}
+
+func resolveForum(ctx context.Context, pm *peers.Manager, input string) (peers.Channel, error) {
+	peer, err := resolvePeer(ctx, pm, input)
+	if err != nil {
+		return peers.Channel{}, err
+	}
+	ch, ok := peer.(peers.Channel)
+	if !ok || !ch.Raw().Forum {
+		return peers.Channel{}, fmt.Errorf("%s is not a forum supergroup", input)
+	}
+	return ch, nil
+}
+
+// createdTopicID finds the topic-created service message; its ID is the topic ID.
+func createdTopicID(updates tg.UpdatesClass) (int, bool) {
+	var list []tg.UpdateClass
+	switch u := updates.(type) {
+	case *tg.Updates:
+		list = u.Updates
+	case *tg.UpdatesCombined:
+		list = u.Updates
+	}
+	for _, upd := range list {
+		u, ok := upd.(*tg.UpdateNewChannelMessage)
+		if !ok {
+			continue
+		}
+		if msg, ok := u.Message.(*tg.MessageService); ok {
+			if _, ok := msg.Action.(*tg.MessageActionTopicCreate); ok {
+				return msg.ID, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func topicItem(t *tg.ForumTopic) types.TopicItem {
+	item := types.TopicItem{
+		ID:          t.ID,
+		Title:       t.Title,
+		Unread:      t.UnreadCount,
+		Mentions:    t.UnreadMentionsCount,
+		Pinned:      t.Pinned,
+		Closed:      t.Closed,
+		Hidden:      t.Hidden,
+		CreatedAt:   unixTime(t.Date),
+		TopMessage:  t.TopMessage,
+		IconEmojiID: t.IconEmojiID,
+	}
+	if id, ok := peerIDFromPeerClass(t.FromID); ok {
+		item.Creator = peerRefFromID(id)
+	}
+	return item
+}
+
+// replyTarget splits a reply header into the replied-to message and the forum
+// topic. A message posted into a topic without replying points at the topic.
+func replyTarget(h *tg.MessageReplyHeader) (replyTo, topic int) {
+	if !h.ForumTopic {
+		return h.ReplyToMsgID, 0
+	}
+	if top, ok := h.GetReplyToTopID(); ok {
+		return h.ReplyToMsgID, top
+	}
+	return 0, h.ReplyToMsgID
+}
+
+// inputReplyTo builds the reply target of a new message. Without a reply
+// ID, a topic ID posts into that topic.
+func inputReplyTo(replyID, topicID int) tg.InputReplyToClass {
+	if replyID == 0 && topicID == 0 {
+		return nil
+	}
+	reply := &tg.InputReplyToMessage{ReplyToMsgID: replyID}
+	if topicID != 0 {
+		reply.SetTopMsgID(topicID)
+		if replyID == 0 {
+			reply.ReplyToMsgID = topicID
+		}
+	}
+	return reply
+}
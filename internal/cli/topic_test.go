@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestReplyTarget(t *testing.T) {
+	plain := &tg.MessageReplyHeader{ReplyToMsgID: 7}
+	if reply, topic := replyTarget(plain); reply != 7 || topic != 0 {
+		t.Fatalf("plain reply = %d, %d", reply, topic)
+	}
+
+	inTopic := &tg.MessageReplyHeader{ForumTopic: true, ReplyToMsgID: 42}
+	if reply, topic := replyTarget(inTopic); reply != 0 || topic != 42 {
+		t.Fatalf("topic message = %d, %d", reply, topic)
+	}
+
+	replyInTopic := &tg.MessageReplyHeader{ForumTopic: true, ReplyToMsgID: 50}
+	replyInTopic.SetReplyToTopID(42)
+	if reply, topic := replyTarget(replyInTopic); reply != 50 || topic != 42 {
+		t.Fatalf("reply in topic = %d, %d", reply, topic)
+	}
+}
+
+func TestInputReplyTo(t *testing.T) {
+	if got := inputReplyTo(0, 0); got != nil {
+		t.Fatalf("expected nil, got %#v", got)
+	}
+
+	cases := []struct {
+		reply, topic    int
+		wantID, wantTop int
+	}{
+		{reply: 5, wantID: 5},
+		{topic: 42, wantID: 42, wantTop: 42},
+		{reply: 50, topic: 42, wantID: 50, wantTop: 42},
+	}
+	for _, tc := range cases {
+		got, ok := inputReplyTo(tc.reply, tc.topic).(*tg.InputReplyToMessage)
+		if !ok {
+			t.Fatalf("inputReplyTo(%d, %d) has unexpected type", tc.reply, tc.topic)
+		}
+		top, _ := got.GetTopMsgID()
+		if got.ReplyToMsgID != tc.wantID || top != tc.wantTop {
+			t.Fatalf("inputReplyTo(%d, %d) = %d/%d, want %d/%d", tc.reply, tc.topic, got.ReplyToMsgID, top, tc.wantID, tc.wantTop)
+		}
+	}
+}
+
+func TestCreatedTopicID(t *testing.T) {
+	updates := &tg.Updates{Updates: []tg.UpdateClass{
+		&tg.UpdateMessageID{ID: 99},
+		&tg.UpdateNewChannelMessage{Message: &tg.MessageService{ID: 99, Action: &tg.MessageActionTopicCreate{Title: "ops"}}},
+	}}
+	if id, ok := createdTopicID(updates); !ok || id != 99 {
+		t.Fatalf("createdTopicID = %d, %v", id, ok)
+	}
+	if _, ok := createdTopicID(&tg.UpdatesTooLong{}); ok {
+		t.Fatal("expected no topic")
+	}
+}
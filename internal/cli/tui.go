@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/gotd/td/constant"
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+
+	"github.com/ghillb/tmgc/internal/tgclient"
+	"github.com/ghillb/tmgc/internal/tui"
+	"github.com/ghillb/tmgc/internal/types"
+)
+
+func newTUICmd() *cobra.Command {
+	var (
+		dialogLimit  int
+		historyLimit int
+	)
+
+	cmd := &cobra.Command{
+		Use:         "tui",
+		Short:       "Full-screen terminal chat client",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{longRunningAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := runtimeFrom(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			// The connection lives for the whole session; --timeout applies per request.
+			factory := tgclient.NewFactory(*rt.Config, rt.Paths, rt.Printer, 0)
+			return factory.Run(cmd.Context(), true, func(ctx context.Context, b *tgclient.Bundle) error {
+				backend := &tuiBackend{bundle: b, dialogLimit: dialogLimit, historyLimit: historyLimit}
+				app := tui.NewApp(backend, rt.Timeout, rt.Printer.NoColor)
+				backend.watch(app)
+
+				ctx, cancel := context.WithCancel(ctx)
+				defer cancel()
+				updatesErr := make(chan error, 1)
+				go func() {
+					updatesErr <- b.ListenUpdates(ctx)
+					cancel()
+				}()
+
+				err := app.Run(ctx)
+				cancel()
+				if uerr := <-updatesErr; uerr != nil && !errors.Is(uerr, context.Canceled) && (err == nil || errors.Is(err, context.Canceled)) {
+					return uerr
+				}
+				if errors.Is(err, context.Canceled) {
+					return nil
+				}
+				return err
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&dialogLimit, "dialogs", 100, "number of dialogs to load")
+	cmd.Flags().IntVar(&historyLimit, "history", 50, "number of messages to load per chat")
+	return cmd
+}
+
+type tuiBackend struct {
+	bundle       *tgclient.Bundle
+	dialogLimit  int
+	historyLimit int
+}
+
+func (t *tuiBackend) Dialogs(ctx context.Context) ([]tui.Dialog, error) {
+	items, err := loadDialogs(ctx, t.bundle, dialogQuery{Limit: t.dialogLimit})
+	if err != nil {
+		return nil, err
+	}
+	dialogs := make([]tui.Dialog, 0, len(items))
+	for _, item := range items {
+		dialogs = append(dialogs, tui.Dialog{
+			PeerRef: item.PeerRef,
+			Title:   item.Title,
+			Unread:  item.UnreadCount,
+			Pinned:  item.Pinned,
+		})
+	}
+	return dialogs, nil
+}
+
+func (t *tuiBackend) History(ctx context.Context, peerRef string) ([]tui.Message, error) {
+	peer, err := resolvePeer(ctx, t.bundle.Peers, peerRef)
+	if err != nil {
+		return nil, err
+	}
+	items, err := loadHistory(ctx, t.bundle, peer.InputPeer(), 0, t.historyLimit, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	list := make([]tui.Message, 0, len(items))
+	for i := len(items) - 1; i >= 0; i-- {
+		list = append(list, tuiMessage(items[i], peerRef, ""))
+	}
+	return list, nil
+}
+
+func (t *tuiBackend) Send(ctx context.Context, peerRef, text string, replyTo int) (tui.Message, error) {
+	peer, err := resolvePeer(ctx, t.bundle.Peers, peerRef)
+	if err != nil {
+		return tui.Message{}, err
+	}
+	req := &tg.MessagesSendMessageRequest{
+		Peer:     peer.InputPeer(),
+		Message:  text,
+		RandomID: rand.Int63(),
+	}
+	if replyTo != 0 {
+		req.ReplyTo = &tg.InputReplyToMessage{ReplyToMsgID: replyTo}
+	}
+	updates, err := t.bundle.Client.API().MessagesSendMessage(ctx, req)
+	if err != nil {
+		return tui.Message{}, err
+	}
+	id, _ := extractSentMessageID(updates)
+	return tui.Message{
+		ID:        id,
+		PeerRef:   peerRef,
+		PeerTitle: peer.VisibleName(),
+		Date:      time.Now(),
+		Sender:    "You",
+		Out:       true,
+		Text:      text,
+		ReplyToID: replyTo,
+	}, nil
+}
+
+func (t *tuiBackend) Edit(ctx context.Context, peerRef string, id int, text string) error {
+	peer, err := resolvePeer(ctx, t.bundle.Peers, peerRef)
+	if err != nil {
+		return err
+	}
+	_, err = t.bundle.Client.API().MessagesEditMessage(ctx, &tg.MessagesEditMessageRequest{
+		Peer:    peer.InputPeer(),
+		ID:      id,
+		Message: text,
+	})
+	return err
+}
+
+func (t *tuiBackend) MarkRead(ctx context.Context, peerRef string, maxID int) error {
+	peer, err := resolvePeer(ctx, t.bundle.Peers, peerRef)
+	if err != nil {
+		return err
+	}
+	return readHistory(ctx, t.bundle.Client.API(), peer, maxID)
+}
+
+// watch forwards new and edited messages and join request notices to the app.
+func (t *tuiBackend) watch(app *tui.App) {
+	push := func(ctx context.Context, kind tui.EventKind, msg tg.MessageClass) {
+		items := buildMessageItems([]tg.MessageClass{msg}, time.Time{})
+		if len(items) == 0 || items[0].PeerID == 0 {
+			return
+		}
+		resolveSenders(ctx, t.bundle.Peers, items)
+		id := constant.TDLibPeerID(items[0].PeerID)
+		title := ""
+		if peer, err := t.bundle.Peers.ResolveTDLibID(ctx, id); err == nil {
+			title = peer.VisibleName()
+		}
+		app.Push(tui.Event{Kind: kind, Message: tuiMessage(items[0], peerRefFromID(id), title)})
+	}
+
+	d := t.bundle.Dispatcher
+	d.OnNewMessage(func(ctx context.Context, e tg.Entities, u *tg.UpdateNewMessage) error {
+		push(ctx, tui.EventNewMessage, u.Message)
+		return nil
+	})
+	d.OnNewChannelMessage(func(ctx context.Context, e tg.Entities, u *tg.UpdateNewChannelMessage) error {
+		push(ctx, tui.EventNewMessage, u.Message)
+		return nil
+	})
+	d.OnEditMessage(func(ctx context.Context, e tg.Entities, u *tg.UpdateEditMessage) error {
+		push(ctx, tui.EventEditMessage, u.Message)
+		return nil
+	})
+	d.OnEditChannelMessage(func(ctx context.Context, e tg.Entities, u *tg.UpdateEditChannelMessage) error {
+		push(ctx, tui.EventEditMessage, u.Message)
+		return nil
+	})
+	d.OnPendingJoinRequests(func(ctx context.Context, e tg.Entities, u *tg.UpdatePendingJoinRequests) error {
+		id, ok := peerIDFromPeerClass(u.Peer)
+		if !ok || u.RequestsPending == 0 {
+			return nil
+		}
+		title := peerRefFromID(id)
+		if peer, err := t.bundle.Peers.ResolveTDLibID(ctx, id); err == nil {
+			title = peer.VisibleName()
+		}
+		app.Push(tui.Event{Kind: tui.EventNotice, Text: fmt.Sprintf("%s: %d pending join request(s)", title, u.RequestsPending)})
+		return nil
+	})
+}
+
+func tuiMessage(item types.MessageItem, peerRef, peerTitle string) tui.Message {
+	sender := item.FromName
+	switch {
+	case item.Out:
+		sender = "You"
+	case sender == "" && senderPeerID(item) != 0:
+		sender = peerRefFromID(constant.TDLibPeerID(senderPeerID(item)))
+	}
+	return tui.Message{
+		ID:        item.ID,
+		PeerRef:   peerRef,
+		PeerTitle: peerTitle,
+		Date:      item.Date,
+		Sender:    sender,
+		Out:       item.Out,
+		Text:      item.Text,
+		ReplyToID: item.ReplyToID,
+	}
+}
@@ -14,6 +14,8 @@ type Paths struct {
 	ConfigPath  string
 	SessionPath string
 	PeersPath   string
+	HistoryPath string
+	CronPath    string
 }
 
 func ResolvePaths(configPath, profile string) (Paths, error) {
@@ -30,6 +32,8 @@ func ResolvePaths(configPath, profile string) (Paths, error) {
 			ConfigPath:  configPath,
 			SessionPath: filepath.Join(profileDir, "session.json"),
 			PeersPath:   filepath.Join(profileDir, "peers.json"),
+			HistoryPath: filepath.Join(profileDir, "shell_history"),
+			CronPath:    filepath.Join(profileDir, "cron_state.json"),
 		}, nil
 	}
 
@@ -46,6 +50,8 @@ func ResolvePaths(configPath, profile string) (Paths, error) {
 		ConfigPath:  filepath.Join(profileDir, "config.json"),
 		SessionPath: filepath.Join(profileDir, "session.json"),
 		PeersPath:   filepath.Join(profileDir, "peers.json"),
+		HistoryPath: filepath.Join(profileDir, "shell_history"),
+		CronPath:    filepath.Join(profileDir, "cron_state.json"),
 	}, nil
 }
 
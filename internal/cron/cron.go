@@ -0,0 +1,179 @@
+// Package cron parses standard five-field cron expressions.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression. Each field is a bit set of the
+// values it matches.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record a "*" day field; when both day fields are
+	// restricted a time matches if either does, as in Vixie cron.
+	domAny, dowAny bool
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: []string{
+		"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec",
+	}}
+	dowField = field{name: "day of week", min: 0, max: 7, names: []string{
+		"sun", "mon", "tue", "wed", "thu", "fri", "sat",
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse reads "minute hour day-of-month month day-of-week" with "*", lists,
+// ranges, steps, month and weekday names, or one of the @daily style macros.
+func Parse(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if m, ok := macros[strings.ToLower(expr)]; ok {
+		expr = m
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return Schedule{}, fmt.Errorf("cron %q: want 5 fields, got %d", expr, len(parts))
+	}
+
+	var (
+		s   Schedule
+		err error
+	)
+	if s.minute, err = minuteField.parse(parts[0]); err != nil {
+		return Schedule{}, err
+	}
+	if s.hour, err = hourField.parse(parts[1]); err != nil {
+		return Schedule{}, err
+	}
+	if s.dom, err = domField.parse(parts[2]); err != nil {
+		return Schedule{}, err
+	}
+	if s.month, err = monthField.parse(parts[3]); err != nil {
+		return Schedule{}, err
+	}
+	if s.dow, err = dowField.parse(parts[4]); err != nil {
+		return Schedule{}, err
+	}
+	// 7 is Sunday as well.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = strings.HasPrefix(parts[2], "*")
+	s.dowAny = strings.HasPrefix(parts[4], "*")
+	return s, nil
+}
+
+func (f field) parse(expr string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("cron %s: invalid step %q", f.name, part)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(from); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = f.value(to); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("cron %s: invalid range %q", f.name, part)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (f field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			if f.min == 1 {
+				return i + 1, nil
+			}
+			return i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("cron %s: invalid value %q (%d-%d)", f.name, s, f.min, f.max)
+	}
+	return n, nil
+}
+
+// Next returns the first matching minute strictly after t, in t's location.
+// It returns the zero time if nothing matches within five years (e.g. Feb 30).
+func (s Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
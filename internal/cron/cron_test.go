@@ -0,0 +1,84 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Fatalf("%q: expected error", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// Monday.
+	from := time.Date(2026, 1, 5, 8, 59, 30, 0, time.UTC)
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"0 8 * * *", time.Date(2026, 1, 6, 8, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"30 9-17/4 * * *", time.Date(2026, 1, 5, 9, 30, 0, 0, time.UTC)},
+		{"0 9 * * sat,sun", time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2026, 1, 11, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 mar *", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches.
+		{"0 12 13 * fri", time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		s, err := Parse(c.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", c.expr, err)
+		}
+		if got := s.Next(from); !got.Equal(c.want) {
+			t.Fatalf("%q: expected %v, got %v", c.expr, c.want, got)
+		}
+	}
+}
+
+func TestNextNever(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Fatalf("expected zero time, got %v", got)
+	}
+}
+
+func TestNextLocation(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tz database")
+	}
+	s, err := Parse("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Across the spring DST change.
+	from := time.Date(2026, 3, 28, 10, 0, 0, 0, loc)
+	want := time.Date(2026, 3, 29, 7, 0, 0, 0, time.UTC)
+	if got := s.Next(from); !got.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,174 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const defaultWidth = 80
+
+type Color string
+
+const (
+	ColorReset   Color = "\x1b[0m"
+	ColorBold    Color = "\x1b[1m"
+	ColorDim     Color = "\x1b[2m"
+	ColorRed     Color = "\x1b[31m"
+	ColorGreen   Color = "\x1b[32m"
+	ColorYellow  Color = "\x1b[33m"
+	ColorBlue    Color = "\x1b[34m"
+	ColorMagenta Color = "\x1b[35m"
+	ColorCyan    Color = "\x1b[36m"
+)
+
+// HumanLayout reports whether the command should use its human layout rather
+// than the generic field renderer.
+func (p *Printer) HumanLayout() bool {
+	return p.Mode == ModeHuman && len(p.Fields) == 0 && p.Template == ""
+}
+
+// Colorize wraps s in the given color when the output is a color-capable terminal.
+func (p *Printer) Colorize(c Color, s string) string {
+	if s == "" || !p.colorEnabled() {
+		return s
+	}
+	return string(c) + s + string(ColorReset)
+}
+
+func (p *Printer) colorEnabled() bool {
+	if p.NoColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := p.Out.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// Width returns the terminal width of the output, or 80 when it is not a terminal.
+func (p *Printer) Width() int {
+	if f, ok := p.Out.(*os.File); ok {
+		if w, _, err := term.GetSize(int(f.Fd())); err == nil && w > 0 {
+			return w
+		}
+	}
+	return defaultWidth
+}
+
+type TranscriptEntry struct {
+	ID       int
+	Date     time.Time
+	Sender   string
+	Username string
+	Out      bool
+	Service  bool
+	Text     string
+	// Reactions is a preformatted summary shown below the text.
+	Reactions string
+}
+
+// Transcript prints messages as a chat log grouped by day.
+func (p *Printer) Transcript(entries []TranscriptEntry) {
+	const indent = "       "
+	width := p.Width() - len(indent)
+	if width < 20 {
+		width = 20
+	}
+
+	var day string
+	for _, e := range entries {
+		if d := e.Date.Format("2006-01-02"); d != day {
+			if day != "" {
+				fmt.Fprintln(p.Out)
+			}
+			day = d
+			fmt.Fprintln(p.Out, p.Colorize(ColorDim, "── "+d+" ──"))
+		}
+
+		sender := e.Sender
+		senderColor := ColorCyan
+		if e.Out {
+			senderColor = ColorGreen
+		}
+		header := p.Colorize(ColorBold+senderColor, sender)
+		if e.Username != "" {
+			header += " " + p.Colorize(ColorMagenta, "@"+strings.TrimPrefix(e.Username, "@"))
+		}
+		header += " " + p.Colorize(ColorDim, fmt.Sprintf("#%d", e.ID))
+		fmt.Fprintf(p.Out, "%s  %s\n", p.Colorize(ColorDim, e.Date.Format("15:04")), strings.TrimSpace(header))
+
+		text := e.Text
+		if e.Service {
+			text = p.Colorize(ColorYellow, text)
+		}
+		for _, line := range Wrap(text, width) {
+			fmt.Fprintln(p.Out, indent+line)
+		}
+		if e.Reactions != "" {
+			fmt.Fprintln(p.Out, indent+p.Colorize(ColorDim, e.Reactions))
+		}
+	}
+}
+
+// Wrap breaks text into lines of at most width runes, splitting on spaces
+// where possible and preserving explicit line breaks.
+func Wrap(text string, width int) []string {
+	if width <= 0 {
+		return strings.Split(text, "\n")
+	}
+
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		words := strings.Fields(para)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		var line strings.Builder
+		lineLen := 0
+		for _, word := range words {
+			wordLen := utf8.RuneCountInString(word)
+			for wordLen > width {
+				if lineLen > 0 {
+					lines = append(lines, line.String())
+					line.Reset()
+					lineLen = 0
+				}
+				head, tail := splitRunes(word, width)
+				lines = append(lines, head)
+				word = tail
+				wordLen = utf8.RuneCountInString(word)
+			}
+			if lineLen > 0 && lineLen+1+wordLen > width {
+				lines = append(lines, line.String())
+				line.Reset()
+				lineLen = 0
+			}
+			if lineLen > 0 {
+				line.WriteByte(' ')
+				lineLen++
+			}
+			line.WriteString(word)
+			lineLen += wordLen
+		}
+		if lineLen > 0 {
+			lines = append(lines, line.String())
+		}
+	}
+	return lines
+}
+
+func splitRunes(s string, n int) (string, string) {
+	i := 0
+	for pos := range s {
+		if i == n {
+			return s[:pos], s[pos:]
+		}
+		i++
+	}
+	return s, ""
+}
@@ -0,0 +1,51 @@
+package output
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{name: "short", text: "hello world", width: 20, want: []string{"hello world"}},
+		{name: "split words", text: "the quick brown fox", width: 9, want: []string{"the quick", "brown fox"}},
+		{name: "long word", text: "abcdefghij", width: 4, want: []string{"abcd", "efgh", "ij"}},
+		{name: "newlines", text: "a\n\nb", width: 10, want: []string{"a", "", "b"}},
+		{name: "runes", text: "привет мир", width: 6, want: []string{"привет", "мир"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Wrap(tt.text, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Wrap() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscriptNoColor(t *testing.T) {
+	var out bytes.Buffer
+	p := NewPrinter(&out, &out, ModeHuman, false)
+	date := time.Date(2026, 1, 5, 9, 30, 0, 0, time.UTC)
+	p.Transcript([]TranscriptEntry{
+		{ID: 1, Date: date, Sender: "Jane Doe", Username: "jane", Text: "hi"},
+		{ID: 2, Date: date.Add(time.Minute), Sender: "You", Out: true, Text: "hello", Reactions: "👍 2"},
+	})
+
+	got := out.String()
+	if strings.Contains(got, "\x1b[") {
+		t.Fatalf("expected no escape codes for non-terminal output, got %q", got)
+	}
+	want := "── 2026-01-05 ──\n09:30  Jane Doe @jane #1\n       hi\n09:31  You #2\n       hello\n       👍 2\n"
+	if got != want {
+		t.Fatalf("Transcript() = %q, want %q", got, want)
+	}
+}
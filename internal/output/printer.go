@@ -1,6 +1,7 @@
 package output
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -17,10 +18,12 @@ const (
 )
 
 type Printer struct {
-	Out     io.Writer
-	Err     io.Writer
-	Mode    Mode
-	NoColor bool
+	Out      io.Writer
+	Err      io.Writer
+	Mode     Mode
+	NoColor  bool
+	Fields   []string
+	Template string
 }
 
 func NewPrinter(out io.Writer, err io.Writer, mode Mode, noColor bool) *Printer {
@@ -38,6 +41,11 @@ func (p *Printer) JSON(v any) error {
 	return enc.Encode(v)
 }
 
+// JSONLine writes v as a single compact JSON line (NDJSON).
+func (p *Printer) JSONLine(v any) error {
+	return json.NewEncoder(p.Out).Encode(v)
+}
+
 func (p *Printer) Plain(lines []string) {
 	for _, line := range lines {
 		fmt.Fprintln(p.Out, line)
@@ -45,11 +53,20 @@ func (p *Printer) Plain(lines []string) {
 }
 
 func (p *Printer) Table(rows [][]string) {
-	w := tabwriter.NewWriter(p.Out, 0, 4, 2, ' ', 0)
+	if len(rows) == 0 {
+		return
+	}
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
 	for _, row := range rows {
 		fmt.Fprintln(w, strings.Join(row, "\t"))
 	}
 	_ = w.Flush()
+
+	// Color the header after alignment so escape codes don't skew column widths.
+	header, rest, _ := strings.Cut(buf.String(), "\n")
+	fmt.Fprintln(p.Out, p.Colorize(ColorBold, header))
+	fmt.Fprint(p.Out, rest)
 }
 
 func (p *Printer) Logf(format string, args ...any) {
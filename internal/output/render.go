@@ -0,0 +1,248 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Render prints v (a struct or a slice of structs) in the printer mode.
+//
+// Columns come from `out` struct tags: `out:"name"` marks a default column,
+// `out:"name,extra"` a column that is only shown when selected via --fields.
+// Fields without an `out` tag can still be selected by their JSON name.
+func (p *Printer) Render(v any) error {
+	items, single := renderItems(v)
+
+	if p.Template != "" {
+		return p.renderTemplate(items)
+	}
+
+	cols, err := selectColumns(elemType(v), p.Fields)
+	if err != nil {
+		return err
+	}
+
+	switch p.Mode {
+	case ModeJSON:
+		if len(p.Fields) == 0 {
+			return p.JSON(v)
+		}
+		objects := make([]map[string]any, 0, len(items))
+		for _, item := range items {
+			obj := make(map[string]any, len(cols))
+			for _, col := range cols {
+				obj[col.key] = item.FieldByIndex(col.index).Interface()
+			}
+			objects = append(objects, obj)
+		}
+		if single && len(objects) == 1 {
+			return p.JSON(objects[0])
+		}
+		return p.JSON(objects)
+	case ModePlain:
+		lines := make([]string, 0, len(items))
+		for _, item := range items {
+			lines = append(lines, strings.Join(formatRow(item, cols), "\t"))
+		}
+		p.Plain(lines)
+	default:
+		header := make([]string, 0, len(cols))
+		for _, col := range cols {
+			header = append(header, strings.ToUpper(col.name))
+		}
+		rows := [][]string{header}
+		for _, item := range items {
+			rows = append(rows, formatRow(item, cols))
+		}
+		p.Table(rows)
+	}
+	return nil
+}
+
+func (p *Printer) renderTemplate(items []reflect.Value) error {
+	tmpl, err := template.New("output").Parse(p.Template)
+	if err != nil {
+		return fmt.Errorf("parse --template: %w", err)
+	}
+	for _, item := range items {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, item.Interface()); err != nil {
+			return fmt.Errorf("execute --template: %w", err)
+		}
+		fmt.Fprintln(p.Out, buf.String())
+	}
+	return nil
+}
+
+type column struct {
+	name  string
+	key   string
+	index []int
+	extra bool
+}
+
+func columnsOf(t reflect.Type) []column {
+	cols := make([]column, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key := strings.Split(f.Tag.Get("json"), ",")[0]
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = f.Name
+		}
+		col := column{name: key, key: key, index: f.Index, extra: true}
+		if tag, ok := f.Tag.Lookup("out"); ok {
+			parts := strings.Split(tag, ",")
+			if parts[0] != "" {
+				col.name = parts[0]
+			}
+			col.extra = len(parts) > 1 && parts[1] == "extra"
+		}
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+func selectColumns(t reflect.Type, fields []string) ([]column, error) {
+	if t == nil {
+		return nil, nil
+	}
+	all := columnsOf(t)
+	if len(fields) == 0 {
+		cols := make([]column, 0, len(all))
+		for _, col := range all {
+			if !col.extra {
+				cols = append(cols, col)
+			}
+		}
+		return cols, nil
+	}
+
+	cols := make([]column, 0, len(fields))
+	for _, field := range fields {
+		name := strings.ToLower(strings.TrimSpace(field))
+		if name == "" {
+			continue
+		}
+		col, ok := findColumn(all, name)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q (available: %s)", field, strings.Join(columnNames(all), ", "))
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+func findColumn(cols []column, name string) (column, bool) {
+	for _, col := range cols {
+		if col.name == name || col.key == name {
+			return col, true
+		}
+	}
+	return column{}, false
+}
+
+func columnNames(cols []column) []string {
+	names := make([]string, 0, len(cols))
+	for _, col := range cols {
+		names = append(names, col.name)
+	}
+	return names
+}
+
+func formatRow(item reflect.Value, cols []column) []string {
+	row := make([]string, 0, len(cols))
+	for _, col := range cols {
+		row = append(row, formatValue(item.FieldByIndex(col.index)))
+	}
+	return row
+}
+
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return ""
+		}
+		return formatValue(v.Elem())
+	}
+
+	switch val := v.Interface().(type) {
+	case time.Time:
+		if val.IsZero() {
+			return ""
+		}
+		return val.Format(time.RFC3339)
+	case time.Duration:
+		return val.String()
+	case fmt.Stringer:
+		return val.String()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			parts = append(parts, formatValue(v.Index(i)))
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+func renderItems(v any) ([]reflect.Value, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, true
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []reflect.Value{rv}, true
+	}
+	items := make([]reflect.Value, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		item := rv.Index(i)
+		for item.Kind() == reflect.Pointer && !item.IsNil() {
+			item = item.Elem()
+		}
+		if item.Kind() == reflect.Pointer {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, false
+}
+
+func elemType(v any) reflect.Type {
+	t := reflect.TypeOf(v)
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
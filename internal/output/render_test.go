@@ -0,0 +1,89 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+type renderItem struct {
+	ID     int       `json:"id" out:"id"`
+	Date   time.Time `json:"date" out:"date"`
+	Text   string    `json:"text,omitempty" out:"text"`
+	PeerID int64     `json:"peer_id" out:"peer_id,extra"`
+	Raw    string    `json:"raw_value"`
+}
+
+func renderTestItems() []renderItem {
+	return []renderItem{
+		{ID: 1, Date: time.Date(2026, 1, 5, 9, 30, 0, 0, time.UTC), Text: "hello", PeerID: 10, Raw: "a"},
+		{ID: 2, Text: "world", PeerID: 20, Raw: "b"},
+	}
+}
+
+func TestRenderPlainDefaultColumns(t *testing.T) {
+	var out bytes.Buffer
+	p := NewPrinter(&out, &out, ModePlain, true)
+	if err := p.Render(renderTestItems()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "1\t2026-01-05T09:30:00Z\thello\n2\t\tworld\n"
+	if out.String() != want {
+		t.Fatalf("Render() = %q, want %q", out.String(), want)
+	}
+}
+
+func TestRenderHumanFields(t *testing.T) {
+	var out bytes.Buffer
+	p := NewPrinter(&out, &out, ModeHuman, true)
+	p.Fields = []string{"peer_id", "raw_value", "ID"}
+	if err := p.Render(renderTestItems()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %q", len(lines), out.String())
+	}
+	if got := strings.Fields(lines[0]); strings.Join(got, " ") != "PEER_ID RAW_VALUE ID" {
+		t.Fatalf("unexpected header %q", lines[0])
+	}
+	if got := strings.Fields(lines[2]); strings.Join(got, " ") != "20 b 2" {
+		t.Fatalf("unexpected row %q", lines[2])
+	}
+}
+
+func TestRenderJSONFields(t *testing.T) {
+	var out bytes.Buffer
+	p := NewPrinter(&out, &out, ModeJSON, true)
+	p.Fields = []string{"id", "text"}
+	if err := p.Render(renderTestItems()[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "{\n  \"id\": 1,\n  \"text\": \"hello\"\n}\n"
+	if out.String() != want {
+		t.Fatalf("Render() = %q, want %q", out.String(), want)
+	}
+}
+
+func TestRenderUnknownField(t *testing.T) {
+	var out bytes.Buffer
+	p := NewPrinter(&out, &out, ModePlain, true)
+	p.Fields = []string{"nope"}
+	err := p.Render(renderTestItems())
+	if err == nil || !strings.Contains(err.Error(), `unknown field "nope"`) {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	var out bytes.Buffer
+	p := NewPrinter(&out, &out, ModeJSON, true)
+	p.Template = "{{.ID}}:{{.Text}}"
+	if err := p.Render(renderTestItems()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "1:hello\n2:world\n" {
+		t.Fatalf("Render() = %q", out.String())
+	}
+}
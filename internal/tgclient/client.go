@@ -8,6 +8,7 @@ import (
 
 	"github.com/gotd/td/telegram"
 	"github.com/gotd/td/telegram/peers"
+	"github.com/gotd/td/telegram/updates"
 	"github.com/gotd/td/tg"
 
 	"github.com/ghillb/tmgc/internal/config"
@@ -17,7 +18,9 @@ import (
 type Bundle struct {
 	Client     *telegram.Client
 	Peers      *peers.Manager
+	PeerStore  *PeerStore
 	Dispatcher *tg.UpdateDispatcher
+	Updates    *updates.Manager
 }
 
 type Factory struct {
@@ -31,20 +34,43 @@ func NewFactory(cfg config.Config, paths config.Paths, printer *output.Printer,
 	return &Factory{Config: cfg, Paths: paths, Printer: printer, Timeout: timeout}
 }
 
+type bundleKey struct{}
+
+// WithBundle attaches an open connection to ctx. Factory.Run reuses it
+// instead of dialing, which lets the shell run many commands on one connection.
+func WithBundle(ctx context.Context, b *Bundle) context.Context {
+	return context.WithValue(ctx, bundleKey{}, b)
+}
+
 func (f *Factory) Run(ctx context.Context, needsAuth bool, fn func(ctx context.Context, b *Bundle) error) error {
+	if b, ok := ctx.Value(bundleKey{}).(*Bundle); ok && b != nil {
+		ctx, cancel := withTimeout(ctx, f.Timeout)
+		defer cancel()
+		return fn(ctx, b)
+	}
+
 	if f.Config.APIID == 0 || f.Config.APIHash == "" {
 		return errors.New("missing API credentials: set TMGC_API_ID and TMGC_API_HASH or run `tmgc auth login --api-id --api-hash`")
 	}
 
-	ctx = withTimeout(ctx, f.Timeout)
+	ctx, cancel := withTimeout(ctx, f.Timeout)
+	defer cancel()
 
 	dispatcher := tg.NewUpdateDispatcher()
 	dispatcherPtr := &dispatcher
 
+	// The peer manager needs the client, so the hook is bound after construction.
+	var handler telegram.UpdateHandler = dispatcherPtr
+	gaps := updates.New(updates.Config{
+		Handler: telegram.UpdateHandlerFunc(func(ctx context.Context, u tg.UpdatesClass) error {
+			return handler.Handle(ctx, u)
+		}),
+	})
+
 	sessionStorage := NewSessionStorage(f.Config, f.Paths, f.Printer)
 	client := telegram.NewClient(f.Config.APIID, f.Config.APIHash, telegram.Options{
 		SessionStorage: sessionStorage,
-		UpdateHandler:  dispatcherPtr,
+		UpdateHandler:  gaps,
 	})
 
 	store, err := NewPeerStore(f.Paths.PeersPath)
@@ -52,11 +78,14 @@ func (f *Factory) Run(ctx context.Context, needsAuth bool, fn func(ctx context.C
 		return err
 	}
 	peerManager := peers.Options{Storage: store, Cache: &peers.InmemoryCache{}}.Build(client.API())
+	handler = peerManager.UpdateHook(dispatcherPtr)
 
 	bundle := &Bundle{
 		Client:     client,
 		Peers:      peerManager,
+		PeerStore:  store,
 		Dispatcher: dispatcherPtr,
+		Updates:    gaps,
 	}
 
 	return client.Run(ctx, func(ctx context.Context) error {
@@ -73,12 +102,28 @@ func (f *Factory) Run(ctx context.Context, needsAuth bool, fn func(ctx context.C
 	})
 }
 
-func withTimeout(ctx context.Context, timeout time.Duration) context.Context {
+// ListenUpdates delivers live updates (with gap recovery) to the dispatcher
+// until ctx is done. Register dispatcher handlers before calling it.
+func (b *Bundle) ListenUpdates(ctx context.Context) error {
+	self, err := b.Client.Self(ctx)
+	if err != nil {
+		return err
+	}
+	return runUpdates(ctx, b.Updates, b.Client.API(), self.ID, self.Bot)
+}
+
+// runUpdates runs m until ctx is done and then resets it, so that a shared
+// connection (e.g. the shell's) can listen again in a later command.
+func runUpdates(ctx context.Context, m *updates.Manager, api updates.API, selfID int64, isBot bool) error {
+	defer m.Reset()
+	return m.Run(ctx, api, selfID, updates.AuthOptions{IsBot: isBot})
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
 	if timeout <= 0 {
-		return ctx
+		return context.WithCancel(ctx)
 	}
-	ctx, _ = context.WithTimeout(ctx, timeout)
-	return ctx
+	return context.WithTimeout(ctx, timeout)
 }
 
 func (f *Factory) Describe() string {
@@ -0,0 +1,41 @@
+package tgclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/updates"
+	"github.com/gotd/td/tg"
+)
+
+// stateAPI serves an empty update state and no differences.
+type stateAPI struct{}
+
+func (stateAPI) UpdatesGetState(context.Context) (*tg.UpdatesState, error) {
+	return &tg.UpdatesState{Pts: 1, Qts: 1, Seq: 1, Date: int(time.Now().Unix())}, nil
+}
+
+func (stateAPI) UpdatesGetDifference(context.Context, *tg.UpdatesGetDifferenceRequest) (tg.UpdatesDifferenceClass, error) {
+	return &tg.UpdatesDifferenceEmpty{Date: int(time.Now().Unix()), Seq: 1}, nil
+}
+
+func (stateAPI) UpdatesGetChannelDifference(context.Context, *tg.UpdatesGetChannelDifferenceRequest) (tg.UpdatesChannelDifferenceClass, error) {
+	return &tg.UpdatesChannelDifferenceEmpty{Pts: 1}, nil
+}
+
+func TestRunUpdatesTwice(t *testing.T) {
+	m := updates.New(updates.Config{
+		Handler: telegram.UpdateHandlerFunc(func(context.Context, tg.UpdatesClass) error { return nil }),
+	})
+	for i := 1; i <= 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		err := runUpdates(ctx, m, stateAPI{}, 42, false)
+		cancel()
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+			t.Fatalf("listener %d: %v", i, err)
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/gotd/td/telegram/peers"
@@ -97,6 +99,30 @@ func (s *PeerStore) SaveContactsHash(ctx context.Context, hash int64) error {
 	return s.persistLocked()
 }
 
+// PeerRefs returns the cached peers as u/c/ch refs, sorted.
+func (s *PeerStore) PeerRefs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refs := make([]string, 0, len(s.data.Peers))
+	for key := range s.data.Peers {
+		for prefix, short := range refPrefixes {
+			if id, ok := strings.CutPrefix(key, prefix); ok {
+				refs = append(refs, short+id)
+				break
+			}
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+var refPrefixes = map[string]string{
+	"users_":   "u",
+	"chats_":   "c",
+	"channel_": "ch",
+}
+
 func (s *PeerStore) load() error {
 	s.ensure()
 	data, err := os.ReadFile(s.path)
@@ -0,0 +1,257 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Backend is the Telegram side of the UI.
+type Backend interface {
+	Dialogs(ctx context.Context) ([]Dialog, error)
+	History(ctx context.Context, peerRef string) ([]Message, error)
+	Send(ctx context.Context, peerRef, text string, replyTo int) (Message, error)
+	Edit(ctx context.Context, peerRef string, id int, text string) error
+	MarkRead(ctx context.Context, peerRef string, maxID int) error
+}
+
+type EventKind int
+
+const (
+	EventNewMessage EventKind = iota
+	EventEditMessage
+	EventNotice
+)
+
+// Event is a live update pushed by the backend.
+type Event struct {
+	Kind    EventKind
+	Message Message
+	Text    string
+}
+
+type App struct {
+	Backend Backend
+	Events  chan Event
+	Timeout time.Duration
+
+	model *Model
+	in    *os.File
+	out   io.Writer
+}
+
+func NewApp(backend Backend, timeout time.Duration, noColor bool) *App {
+	return &App{
+		Backend: backend,
+		Events:  make(chan Event, 256),
+		Timeout: timeout,
+		model:   NewModel(noColor),
+		in:      os.Stdin,
+		out:     os.Stdout,
+	}
+}
+
+// Push queues a live event without blocking the update handler.
+func (a *App) Push(ev Event) {
+	select {
+	case a.Events <- ev:
+	default:
+	}
+}
+
+// Run takes over the terminal until the user quits or ctx is done.
+func (a *App) Run(ctx context.Context) error {
+	fd := int(a.in.Fd())
+	if !term.IsTerminal(fd) {
+		return errors.New("tui requires an interactive terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(a.out, "\x1b[?1049h")
+	defer func() {
+		fmt.Fprint(a.out, "\x1b[?1049l\x1b[?25h")
+		_ = term.Restore(fd, state)
+	}()
+
+	if err := a.call(ctx, func(ctx context.Context) error {
+		dialogs, err := a.Backend.Dialogs(ctx)
+		if err != nil {
+			return err
+		}
+		a.model.SetDialogs(dialogs)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if d, ok := a.model.Current(); ok {
+		a.perform(ctx, Action{Kind: ActionOpen, PeerRef: d.PeerRef})
+	}
+
+	keys, readErr, stopKeys := a.startKeys()
+	// Stop reading before the terminal is handed back, so input typed after
+	// quitting reaches the caller (e.g. the shell prompt).
+	defer stopKeys()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		a.draw(fd)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case batch := <-keys:
+			for _, k := range batch {
+				action := a.model.HandleKey(k)
+				if action.Kind == ActionQuit {
+					return nil
+				}
+				a.perform(ctx, action)
+			}
+		case ev := <-a.Events:
+			switch ev.Kind {
+			case EventNewMessage:
+				a.model.AddMessage(ev.Message)
+			case EventEditMessage:
+				a.model.UpdateMessage(ev.Message)
+			case EventNotice:
+				a.model.SetStatus("%s", ev.Text)
+			}
+		case <-ticker.C:
+			// Redraw picks up terminal resizes.
+		}
+	}
+}
+
+// keyPollInterval bounds how long the key reader takes to notice a stop.
+const keyPollInterval = 50 * time.Millisecond
+
+// startKeys reads keys from the terminal until stop is called. The reader
+// only reads when input is waiting, so nothing is consumed after stop returns.
+func (a *App) startKeys() (<-chan []Key, <-chan error, func()) {
+	keys := make(chan []Key)
+	errs := make(chan error, 1)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+		buf := make([]byte, 256)
+		var pending []byte
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			ready, err := waitReadable(a.in, keyPollInterval)
+			if err == nil && ready {
+				var n int
+				n, err = a.in.Read(buf)
+				if err == nil {
+					var batch []Key
+					batch, pending = ParseKeys(append(pending, buf[:n]...))
+					if len(batch) > 0 {
+						select {
+						case keys <- batch:
+						case <-done:
+							return
+						}
+					}
+				}
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	stop := func() {
+		close(done)
+		<-exited
+	}
+	return keys, errs, stop
+}
+
+func (a *App) perform(ctx context.Context, action Action) {
+	var err error
+	switch action.Kind {
+	case ActionOpen:
+		err = a.call(ctx, func(ctx context.Context) error {
+			list, err := a.Backend.History(ctx, action.PeerRef)
+			if err != nil {
+				return err
+			}
+			a.model.SetHistory(action.PeerRef, list)
+			return nil
+		})
+	case ActionSend:
+		err = a.call(ctx, func(ctx context.Context) error {
+			msg, err := a.Backend.Send(ctx, action.PeerRef, action.Text, action.ID)
+			if err != nil {
+				return err
+			}
+			a.model.AddMessage(msg)
+			return nil
+		})
+	case ActionEdit:
+		err = a.call(ctx, func(ctx context.Context) error {
+			if err := a.Backend.Edit(ctx, action.PeerRef, action.ID, action.Text); err != nil {
+				return err
+			}
+			a.model.UpdateMessage(Message{ID: action.ID, PeerRef: action.PeerRef, Text: action.Text})
+			return nil
+		})
+	case ActionMarkRead:
+		err = a.call(ctx, func(ctx context.Context) error {
+			if err := a.Backend.MarkRead(ctx, action.PeerRef, action.ID); err != nil {
+				return err
+			}
+			a.model.MarkedRead(action.PeerRef)
+			return nil
+		})
+	}
+	if err != nil {
+		a.model.SetStatus("error: %v", err)
+	}
+}
+
+func (a *App) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	if a.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.Timeout)
+		defer cancel()
+	}
+	return fn(ctx)
+}
+
+func (a *App) draw(fd int) {
+	width, height, err := term.GetSize(fd)
+	if err != nil {
+		width, height = 80, 24
+	}
+	lines := a.model.Render(width, height)
+
+	var b strings.Builder
+	b.WriteString("\x1b[?25l\x1b[H")
+	for i, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\x1b[K")
+		if i < len(lines)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	fmt.Fprintf(&b, "\x1b[%d;%dH\x1b[?25h", height, a.model.CursorColumn(width))
+	fmt.Fprint(a.out, b.String())
+}
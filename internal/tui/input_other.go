@@ -0,0 +1,13 @@
+//go:build !unix && !windows
+
+package tui
+
+import (
+	"os"
+	"time"
+)
+
+// waitReadable cannot poll on this platform; reads block until input.
+func waitReadable(f *os.File, timeout time.Duration) (bool, error) {
+	return true, nil
+}
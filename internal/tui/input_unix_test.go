@@ -0,0 +1,47 @@
+//go:build unix
+
+package tui
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStartKeysLeavesInputAfterStop(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	a := &App{in: r}
+	keys, _, stop := a.startKeys()
+	if _, err := w.Write([]byte("q")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case batch := <-keys:
+		if want := []Key{{Code: KeyRune, Rune: 'q'}}; !reflect.DeepEqual(batch, want) {
+			t.Fatalf("keys = %+v, want %+v", batch, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no key read")
+	}
+	stop()
+
+	if _, err := w.Write([]byte("ls\n")); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	rest, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rest) != "ls\n" {
+		t.Fatalf("input after stop = %q, want %q", rest, "ls\n")
+	}
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package tui
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// waitReadable reports whether f has input within timeout.
+func waitReadable(f *os.File, timeout time.Duration) (bool, error) {
+	event, err := windows.WaitForSingleObject(windows.Handle(f.Fd()), uint32(timeout/time.Millisecond))
+	switch event {
+	case windows.WAIT_OBJECT_0:
+		return true, nil
+	case uint32(windows.WAIT_TIMEOUT):
+		return false, nil
+	}
+	return false, err
+}
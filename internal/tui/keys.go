@@ -0,0 +1,97 @@
+package tui
+
+import "unicode/utf8"
+
+type KeyCode int
+
+const (
+	KeyRune KeyCode = iota
+	KeyCtrl
+	KeyEnter
+	KeyBackspace
+	KeyEsc
+	KeyUp
+	KeyDown
+	KeyPgUp
+	KeyPgDn
+)
+
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+var escapeKeys = map[string]KeyCode{
+	"\x1b[A":  KeyUp,
+	"\x1b[B":  KeyDown,
+	"\x1bOA":  KeyUp,
+	"\x1bOB":  KeyDown,
+	"\x1b[5~": KeyPgUp,
+	"\x1b[6~": KeyPgDn,
+}
+
+// ParseKeys decodes raw terminal input into keys. Unknown escape sequences
+// are dropped; an incomplete UTF-8 tail is returned for the next read.
+func ParseKeys(buf []byte) ([]Key, []byte) {
+	var keys []Key
+	for len(buf) > 0 {
+		b := buf[0]
+		switch {
+		case b == 0x1b:
+			if len(buf) == 1 {
+				keys = append(keys, Key{Code: KeyEsc})
+				buf = buf[1:]
+				continue
+			}
+			n := escapeLen(buf)
+			if code, ok := escapeKeys[string(buf[:n])]; ok {
+				keys = append(keys, Key{Code: code})
+			}
+			buf = buf[n:]
+		case b == '\r' || b == '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+			buf = buf[1:]
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, Key{Code: KeyBackspace})
+			buf = buf[1:]
+		case b == '\t':
+			keys = append(keys, Key{Code: KeyRune, Rune: ' '})
+			buf = buf[1:]
+		case b < 0x20:
+			keys = append(keys, Key{Code: KeyCtrl, Rune: rune('a' + b - 1)})
+			buf = buf[1:]
+		default:
+			if !utf8.FullRune(buf) {
+				return keys, buf
+			}
+			r, size := utf8.DecodeRune(buf)
+			keys = append(keys, Key{Code: KeyRune, Rune: r})
+			buf = buf[size:]
+		}
+	}
+	return keys, nil
+}
+
+// escapeLen returns the length of the escape sequence at the start of buf.
+func escapeLen(buf []byte) int {
+	if len(buf) < 2 {
+		return len(buf)
+	}
+	switch buf[1] {
+	case '[':
+		for i := 2; i < len(buf); i++ {
+			if buf[i] >= 0x40 && buf[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(buf)
+	case 'O':
+		if len(buf) >= 3 {
+			return 3
+		}
+		return len(buf)
+	default:
+		// Alt+key: ignore the key.
+		return 2
+	}
+}
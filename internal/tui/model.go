@@ -0,0 +1,514 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ghillb/tmgc/internal/output"
+)
+
+type Dialog struct {
+	PeerRef string
+	Title   string
+	Unread  int
+	Pinned  bool
+}
+
+type Message struct {
+	ID        int
+	PeerRef   string
+	PeerTitle string
+	Date      time.Time
+	Sender    string
+	Out       bool
+	Text      string
+	ReplyToID int
+}
+
+type ActionKind int
+
+const (
+	ActionNone ActionKind = iota
+	ActionQuit
+	ActionOpen
+	ActionSend
+	ActionEdit
+	ActionMarkRead
+)
+
+// Action is a side effect requested by the model; the app performs it
+// through the Backend and feeds the result back.
+type Action struct {
+	Kind    ActionKind
+	PeerRef string
+	Text    string
+	ID      int
+}
+
+type composeMode int
+
+const (
+	composeNew composeMode = iota
+	composeReply
+	composeEdit
+)
+
+// Model is the UI state. It is only touched from the app loop.
+type Model struct {
+	Dialogs  []Dialog
+	Selected int
+	History  map[string][]Message
+
+	lastID   map[string]int
+	compose  []rune
+	mode     composeMode
+	targetID int
+	scroll   int
+	status   string
+	noColor  bool
+}
+
+func NewModel(noColor bool) *Model {
+	return &Model{
+		History: make(map[string][]Message),
+		lastID:  make(map[string]int),
+		noColor: noColor,
+	}
+}
+
+func (m *Model) Current() (Dialog, bool) {
+	if m.Selected < 0 || m.Selected >= len(m.Dialogs) {
+		return Dialog{}, false
+	}
+	return m.Dialogs[m.Selected], true
+}
+
+func (m *Model) SetStatus(format string, args ...any) {
+	m.status = fmt.Sprintf(format, args...)
+}
+
+func (m *Model) SetDialogs(dialogs []Dialog) {
+	current, ok := m.Current()
+	m.Dialogs = dialogs
+	m.Selected = 0
+	if ok {
+		m.selectPeer(current.PeerRef)
+	}
+}
+
+func (m *Model) SetHistory(peerRef string, messages []Message) {
+	m.History[peerRef] = messages
+	for _, msg := range messages {
+		if msg.ID > m.lastID[peerRef] {
+			m.lastID[peerRef] = msg.ID
+		}
+	}
+}
+
+// AddMessage appends a live message, bumps its dialog to the top and counts
+// it as unread unless it is outgoing.
+func (m *Model) AddMessage(msg Message) {
+	// Updates can repeat (e.g. our own sends); IDs only grow within a chat.
+	if msg.ID <= m.lastID[msg.PeerRef] {
+		return
+	}
+	m.lastID[msg.PeerRef] = msg.ID
+	if list, loaded := m.History[msg.PeerRef]; loaded {
+		m.History[msg.PeerRef] = append(list, msg)
+	}
+
+	current, hasCurrent := m.Current()
+	idx := m.dialogIndex(msg.PeerRef)
+	var d Dialog
+	if idx >= 0 {
+		d = m.Dialogs[idx]
+		m.Dialogs = append(m.Dialogs[:idx], m.Dialogs[idx+1:]...)
+	} else {
+		d = Dialog{PeerRef: msg.PeerRef, Title: msg.PeerTitle}
+		if d.Title == "" {
+			d.Title = msg.PeerRef
+		}
+	}
+	if !msg.Out {
+		d.Unread++
+	}
+	m.Dialogs = append([]Dialog{d}, m.Dialogs...)
+	if hasCurrent {
+		m.selectPeer(current.PeerRef)
+	}
+}
+
+func (m *Model) UpdateMessage(msg Message) {
+	list := m.History[msg.PeerRef]
+	for i := range list {
+		if list[i].ID == msg.ID {
+			list[i].Text = msg.Text
+			return
+		}
+	}
+}
+
+func (m *Model) MarkedRead(peerRef string) {
+	if idx := m.dialogIndex(peerRef); idx >= 0 {
+		m.Dialogs[idx].Unread = 0
+	}
+}
+
+func (m *Model) dialogIndex(peerRef string) int {
+	for i, d := range m.Dialogs {
+		if d.PeerRef == peerRef {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Model) selectPeer(peerRef string) {
+	if idx := m.dialogIndex(peerRef); idx >= 0 {
+		m.Selected = idx
+	}
+}
+
+// HandleKey applies a key press and returns the action the app should run.
+func (m *Model) HandleKey(k Key) Action {
+	m.status = ""
+	switch k.Code {
+	case KeyCtrl:
+		switch k.Rune {
+		case 'c', 'q':
+			return Action{Kind: ActionQuit}
+		case 'n':
+			return m.move(1)
+		case 'p':
+			return m.move(-1)
+		case 'g':
+			return m.nextUnread()
+		case 'r':
+			return m.markRead()
+		case 'e':
+			m.startEdit()
+		case 'y':
+			m.startReply()
+		case 'w':
+			m.deleteWord()
+		case 'u':
+			m.compose = m.compose[:0]
+		}
+	case KeyUp:
+		return m.move(-1)
+	case KeyDown:
+		return m.move(1)
+	case KeyPgUp:
+		m.scroll += 10
+	case KeyPgDn:
+		m.scroll -= 10
+		if m.scroll < 0 {
+			m.scroll = 0
+		}
+	case KeyEsc:
+		m.cancelCompose()
+	case KeyBackspace:
+		if n := len(m.compose); n > 0 {
+			m.compose = m.compose[:n-1]
+		}
+	case KeyEnter:
+		return m.submit()
+	case KeyRune:
+		m.compose = append(m.compose, k.Rune)
+	}
+	return Action{}
+}
+
+func (m *Model) move(delta int) Action {
+	if len(m.Dialogs) == 0 {
+		return Action{}
+	}
+	m.Selected = (m.Selected + delta + len(m.Dialogs)) % len(m.Dialogs)
+	return m.open()
+}
+
+func (m *Model) nextUnread() Action {
+	for i := 1; i <= len(m.Dialogs); i++ {
+		idx := (m.Selected + i) % len(m.Dialogs)
+		if m.Dialogs[idx].Unread > 0 {
+			m.Selected = idx
+			return m.open()
+		}
+	}
+	m.SetStatus("no unread chats")
+	return Action{}
+}
+
+func (m *Model) open() Action {
+	m.scroll = 0
+	m.cancelCompose()
+	d, ok := m.Current()
+	if !ok {
+		return Action{}
+	}
+	if _, loaded := m.History[d.PeerRef]; loaded {
+		return Action{}
+	}
+	return Action{Kind: ActionOpen, PeerRef: d.PeerRef}
+}
+
+func (m *Model) markRead() Action {
+	d, ok := m.Current()
+	if !ok {
+		return Action{}
+	}
+	maxID := 0
+	for _, msg := range m.History[d.PeerRef] {
+		if msg.ID > maxID {
+			maxID = msg.ID
+		}
+	}
+	return Action{Kind: ActionMarkRead, PeerRef: d.PeerRef, ID: maxID}
+}
+
+func (m *Model) startEdit() {
+	d, ok := m.Current()
+	if !ok {
+		return
+	}
+	list := m.History[d.PeerRef]
+	for i := len(list) - 1; i >= 0; i-- {
+		if list[i].Out {
+			m.mode = composeEdit
+			m.targetID = list[i].ID
+			m.compose = []rune(list[i].Text)
+			return
+		}
+	}
+	m.SetStatus("no own message to edit")
+}
+
+func (m *Model) startReply() {
+	d, ok := m.Current()
+	if !ok {
+		return
+	}
+	list := m.History[d.PeerRef]
+	for i := len(list) - 1; i >= 0; i-- {
+		if !list[i].Out {
+			m.mode = composeReply
+			m.targetID = list[i].ID
+			return
+		}
+	}
+	m.SetStatus("no message to reply to")
+}
+
+func (m *Model) cancelCompose() {
+	if m.mode == composeEdit {
+		m.compose = m.compose[:0]
+	}
+	m.mode = composeNew
+	m.targetID = 0
+}
+
+func (m *Model) deleteWord() {
+	i := len(m.compose)
+	for i > 0 && m.compose[i-1] == ' ' {
+		i--
+	}
+	for i > 0 && m.compose[i-1] != ' ' {
+		i--
+	}
+	m.compose = m.compose[:i]
+}
+
+func (m *Model) submit() Action {
+	d, ok := m.Current()
+	text := strings.TrimSpace(string(m.compose))
+	if !ok || text == "" {
+		return Action{}
+	}
+
+	action := Action{Kind: ActionSend, PeerRef: d.PeerRef, Text: text}
+	switch m.mode {
+	case composeEdit:
+		action.Kind = ActionEdit
+		action.ID = m.targetID
+	case composeReply:
+		action.ID = m.targetID
+	}
+	m.compose = m.compose[:0]
+	m.mode = composeNew
+	m.targetID = 0
+	m.scroll = 0
+	return action
+}
+
+// Render draws the full screen as width-padded lines.
+func (m *Model) Render(width, height int) []string {
+	if width < 20 || height < 5 {
+		return []string{truncate("terminal too small", width)}
+	}
+
+	left := width / 3
+	if left > 30 {
+		left = 30
+	}
+	right := width - left - 1
+	body := height - 3
+
+	title := " tmgc"
+	if d, ok := m.Current(); ok {
+		title += " — " + d.Title
+	}
+	lines := []string{m.color(output.ColorBold, pad(title, width))}
+
+	dialogs := m.renderDialogs(left, body)
+	history := m.renderHistory(right, body)
+	for i := 0; i < body; i++ {
+		lines = append(lines, dialogs[i]+m.color(output.ColorDim, "│")+history[i])
+	}
+
+	lines = append(lines, m.color(output.ColorDim, pad(m.statusLine(), width)))
+	lines = append(lines, pad(m.composeLine(width), width))
+	return lines
+}
+
+// CursorColumn returns the 1-based column of the cursor on the compose line.
+func (m *Model) CursorColumn(width int) int {
+	col := utf8.RuneCountInString(m.composeLine(width)) + 1
+	if col > width {
+		col = width
+	}
+	return col
+}
+
+func (m *Model) renderDialogs(width, height int) []string {
+	lines := make([]string, height)
+	start := 0
+	if m.Selected >= height {
+		start = m.Selected - height + 1
+	}
+	for i := 0; i < height; i++ {
+		idx := start + i
+		if idx >= len(m.Dialogs) {
+			lines[i] = pad("", width)
+			continue
+		}
+		d := m.Dialogs[idx]
+		badge := ""
+		if d.Unread > 0 {
+			badge = fmt.Sprintf(" (%d)", d.Unread)
+		}
+		marker := " "
+		if d.Pinned {
+			marker = "*"
+		}
+		name := truncate(marker+d.Title, width-utf8.RuneCountInString(badge))
+		line := pad(name+badge, width)
+		switch {
+		case idx == m.Selected:
+			line = m.color("\x1b[7m", line)
+		case d.Unread > 0:
+			line = m.color(output.ColorBold, line)
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+func (m *Model) renderHistory(width, height int) []string {
+	var all []string
+	if d, ok := m.Current(); ok {
+		list, loaded := m.History[d.PeerRef]
+		if !loaded {
+			all = append(all, pad(" loading…", width))
+		}
+		for _, msg := range list {
+			senderColor := output.ColorCyan
+			if msg.Out {
+				senderColor = output.ColorGreen
+			}
+			header := " " + msg.Date.Format("15:04") + " "
+			meta := fmt.Sprintf(" #%d", msg.ID)
+			if msg.ReplyToID != 0 {
+				meta += fmt.Sprintf(" ↩%d", msg.ReplyToID)
+			}
+			sender := truncate(msg.Sender, width-utf8.RuneCountInString(header+meta))
+			plain := header + sender + meta
+			all = append(all, m.color(output.ColorDim, header)+m.color(senderColor, sender)+m.color(output.ColorDim, meta)+strings.Repeat(" ", max(0, width-utf8.RuneCountInString(plain))))
+			for _, line := range output.Wrap(msg.Text, width-3) {
+				all = append(all, pad("   "+line, width))
+			}
+		}
+	}
+
+	maxScroll := len(all) - height
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	if m.scroll > maxScroll {
+		m.scroll = maxScroll
+	}
+	end := len(all) - m.scroll
+	start := end - height
+	if start < 0 {
+		start = 0
+	}
+	visible := all[start:end]
+
+	lines := make([]string, 0, height)
+	for len(lines)+len(visible) < height {
+		lines = append(lines, pad("", width))
+	}
+	return append(lines, visible...)
+}
+
+func (m *Model) statusLine() string {
+	switch {
+	case m.status != "":
+		return " " + m.status
+	case m.mode == composeReply:
+		return fmt.Sprintf(" replying to #%d (Esc to cancel)", m.targetID)
+	case m.mode == composeEdit:
+		return fmt.Sprintf(" editing #%d (Esc to cancel)", m.targetID)
+	default:
+		return " ↑/↓ chats  ^G next unread  ^R mark read  ^Y reply  ^E edit  PgUp/PgDn scroll  ^Q quit"
+	}
+}
+
+func (m *Model) composeLine(width int) string {
+	prompt := "> "
+	text := m.compose
+	if room := width - len(prompt) - 1; len(text) > room && room > 0 {
+		text = text[len(text)-room:]
+	}
+	return prompt + string(text)
+}
+
+func (m *Model) color(c output.Color, s string) string {
+	if m.noColor && c != "\x1b[7m" {
+		return s
+	}
+	return string(c) + s + string(output.ColorReset)
+}
+
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	if width == 1 {
+		return string(r[:1])
+	}
+	return string(r[:width-1]) + "…"
+}
+
+func pad(s string, width int) string {
+	s = truncate(s, width)
+	if n := utf8.RuneCountInString(s); n < width {
+		s += strings.Repeat(" ", width-n)
+	}
+	return s
+}
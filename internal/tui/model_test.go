@@ -0,0 +1,152 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys, rest := ParseKeys([]byte("hi\x1b[A\x0e\r\x7f\x1b[6~é"))
+	want := []Key{
+		{Code: KeyRune, Rune: 'h'},
+		{Code: KeyRune, Rune: 'i'},
+		{Code: KeyUp},
+		{Code: KeyCtrl, Rune: 'n'},
+		{Code: KeyEnter},
+		{Code: KeyBackspace},
+		{Code: KeyPgDn},
+		{Code: KeyRune, Rune: 'é'},
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("ParseKeys() = %+v, want %+v", keys, want)
+	}
+	if len(rest) != 0 {
+		t.Fatalf("unexpected rest %q", rest)
+	}
+
+	keys, rest = ParseKeys([]byte{'a', 0xc3})
+	if len(keys) != 1 || !reflect.DeepEqual(rest, []byte{0xc3}) {
+		t.Fatalf("expected partial rune to be kept, got keys=%+v rest=%q", keys, rest)
+	}
+}
+
+func testModel() *Model {
+	m := NewModel(true)
+	m.SetDialogs([]Dialog{
+		{PeerRef: "u1", Title: "Alice"},
+		{PeerRef: "u2", Title: "Bob", Unread: 2},
+		{PeerRef: "ch3", Title: "News"},
+	})
+	m.SetHistory("u1", []Message{
+		{ID: 10, PeerRef: "u1", Sender: "Alice", Text: "hi"},
+		{ID: 11, PeerRef: "u1", Sender: "You", Out: true, Text: "hello"},
+	})
+	return m
+}
+
+func typeText(m *Model, s string) {
+	for _, r := range s {
+		m.HandleKey(Key{Code: KeyRune, Rune: r})
+	}
+}
+
+func TestModelNavigation(t *testing.T) {
+	m := testModel()
+
+	if a := m.HandleKey(Key{Code: KeyDown}); a.Kind != ActionOpen || a.PeerRef != "u2" {
+		t.Fatalf("expected open u2, got %+v", a)
+	}
+	if a := m.HandleKey(Key{Code: KeyUp}); a.Kind != ActionNone {
+		t.Fatalf("expected no reload for loaded history, got %+v", a)
+	}
+	if a := m.HandleKey(Key{Code: KeyCtrl, Rune: 'g'}); a.PeerRef != "u2" {
+		t.Fatalf("expected jump to unread u2, got %+v", a)
+	}
+	if a := m.HandleKey(Key{Code: KeyCtrl, Rune: 'r'}); a.Kind != ActionMarkRead || a.PeerRef != "u2" {
+		t.Fatalf("expected mark read, got %+v", a)
+	}
+	m.MarkedRead("u2")
+	if m.Dialogs[1].Unread != 0 {
+		t.Fatalf("expected unread cleared")
+	}
+}
+
+func TestModelCompose(t *testing.T) {
+	m := testModel()
+
+	typeText(m, "yo")
+	if a := m.HandleKey(Key{Code: KeyEnter}); a.Kind != ActionSend || a.Text != "yo" || a.ID != 0 {
+		t.Fatalf("expected send, got %+v", a)
+	}
+
+	m.HandleKey(Key{Code: KeyCtrl, Rune: 'y'})
+	typeText(m, "sure")
+	if a := m.HandleKey(Key{Code: KeyEnter}); a.Kind != ActionSend || a.ID != 10 {
+		t.Fatalf("expected reply to 10, got %+v", a)
+	}
+
+	m.HandleKey(Key{Code: KeyCtrl, Rune: 'e'})
+	m.HandleKey(Key{Code: KeyCtrl, Rune: 'w'})
+	typeText(m, "there")
+	if a := m.HandleKey(Key{Code: KeyEnter}); a.Kind != ActionEdit || a.ID != 11 || a.Text != "there" {
+		t.Fatalf("expected edit of 11, got %+v", a)
+	}
+}
+
+func TestModelAddMessage(t *testing.T) {
+	m := testModel()
+	m.AddMessage(Message{ID: 5, PeerRef: "ch3", Text: "breaking"})
+	m.AddMessage(Message{ID: 5, PeerRef: "ch3", Text: "breaking"})
+	m.AddMessage(Message{ID: 1, PeerRef: "u9", PeerTitle: "Carol", Text: "new"})
+
+	if m.Dialogs[0].PeerRef != "u9" || m.Dialogs[1].PeerRef != "ch3" {
+		t.Fatalf("expected bumped dialogs, got %+v", m.Dialogs)
+	}
+	if m.Dialogs[1].Unread != 1 {
+		t.Fatalf("expected 1 unread for ch3, got %d", m.Dialogs[1].Unread)
+	}
+	if cur, _ := m.Current(); cur.PeerRef != "u1" {
+		t.Fatalf("expected selection to stay on u1, got %s", cur.PeerRef)
+	}
+
+	m.AddMessage(Message{ID: 12, PeerRef: "u1", Sender: "Alice", Text: "ping"})
+	if n := len(m.History["u1"]); n != 3 {
+		t.Fatalf("expected appended history, got %d", n)
+	}
+}
+
+func TestModelRender(t *testing.T) {
+	m := testModel()
+	lines := m.Render(60, 10)
+	if len(lines) != 10 {
+		t.Fatalf("expected 10 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		plain := stripANSI(line)
+		if n := utf8.RuneCountInString(plain); n != 60 {
+			t.Fatalf("line %d has width %d: %q", i, n, plain)
+		}
+	}
+	screen := stripANSI(strings.Join(lines, "\n"))
+	for _, want := range []string{"Alice", "Bob (2)", "hello", "#11"} {
+		if !strings.Contains(screen, want) {
+			t.Fatalf("expected %q on screen:\n%s", want, screen)
+		}
+	}
+}
+
+func stripANSI(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0x1b {
+			for i < len(s) && s[i] != 'm' {
+				i++
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
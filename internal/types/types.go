@@ -1,44 +1,243 @@
 package types
 
-import "time"
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
 
 type AuthStatus struct {
-	Authorized bool   `json:"authorized"`
-	UserID     int64  `json:"user_id,omitempty"`
-	Username   string `json:"username,omitempty"`
-	Phone      string `json:"phone,omitempty"`
-	IsBot      bool   `json:"is_bot"`
+	Authorized bool   `json:"authorized" out:"authorized"`
+	UserID     int64  `json:"user_id,omitempty" out:"user"`
+	Username   string `json:"username,omitempty" out:"username"`
+	Phone      string `json:"phone,omitempty" out:"phone"`
+	IsBot      bool   `json:"is_bot" out:"bot"`
 }
 
 type ChatListItem struct {
-	PeerID        int64  `json:"peer_id"`
-	PeerRef       string `json:"peer_ref"`
-	PeerType      string `json:"peer_type"`
-	Title         string `json:"title"`
-	Username      string `json:"username,omitempty"`
-	UnreadCount   int    `json:"unread_count"`
-	LastMessageID int    `json:"last_message_id,omitempty"`
-	Pinned        bool   `json:"pinned"`
+	PeerID          int64     `json:"peer_id" out:"peer_id,extra"`
+	PeerRef         string    `json:"peer_ref" out:"peer"`
+	PeerType        string    `json:"peer_type" out:"type"`
+	Kind            string    `json:"kind" out:"kind,extra"`
+	Title           string    `json:"title" out:"title"`
+	Username        string    `json:"username,omitempty" out:"username"`
+	UnreadCount     int       `json:"unread_count" out:"unread"`
+	UnreadMentions  int       `json:"unread_mentions,omitempty" out:"mentions,extra"`
+	UnreadReactions int       `json:"unread_reactions,omitempty" out:"reactions,extra"`
+	UnreadMark      bool      `json:"unread_mark,omitempty" out:"unread_mark,extra"`
+	LastMessageID   int       `json:"last_message_id,omitempty" out:"top"`
+	Pinned          bool      `json:"pinned" out:"pinned"`
+	Muted           bool      `json:"muted,omitempty" out:"muted,extra"`
+	Contact         bool      `json:"contact,omitempty" out:"contact,extra"`
+	Archived        bool      `json:"archived,omitempty" out:"archived,extra"`
+	LastMessageDate time.Time `json:"last_message_date,omitzero" out:"date,extra"`
+	Preview         string    `json:"preview,omitempty" out:"preview,extra"`
+}
+
+type FolderItem struct {
+	ID       int      `json:"id" out:"id"`
+	Title    string   `json:"title" out:"title"`
+	Emoticon string   `json:"emoticon,omitempty" out:"emoticon"`
+	Flags    []string `json:"flags,omitempty" out:"flags"`
+	Shared   bool     `json:"shared,omitempty" out:"shared,extra"`
+	Pinned   []string `json:"pinned,omitempty" out:"pinned,extra"`
+	Include  []string `json:"include,omitempty" out:"include,extra"`
+	Exclude  []string `json:"exclude,omitempty" out:"exclude,extra"`
+}
+
+type FolderPeerItem struct {
+	PeerRef  string `json:"peer_ref" out:"peer"`
+	Title    string `json:"title" out:"title"`
+	Username string `json:"username,omitempty" out:"username"`
+	Role     string `json:"role" out:"role"`
+}
+
+type MemberItem struct {
+	PeerRef      string    `json:"peer_ref" out:"peer"`
+	Name         string    `json:"name" out:"name"`
+	Username     string    `json:"username,omitempty" out:"username"`
+	Role         string    `json:"role" out:"role"`
+	Rank         string    `json:"rank,omitempty" out:"rank,extra"`
+	JoinedAt     time.Time `json:"joined_at,omitzero" out:"joined"`
+	Inviter      string    `json:"inviter,omitempty" out:"inviter,extra"`
+	Until        time.Time `json:"until,omitzero" out:"until,extra"`
+	Rights       []string  `json:"rights,omitempty" out:"rights,extra"`
+	Restrictions []string  `json:"restrictions,omitempty" out:"restrictions,extra"`
+}
+
+type TopicItem struct {
+	ID          int       `json:"id" out:"id"`
+	Title       string    `json:"title" out:"title"`
+	Unread      int       `json:"unread" out:"unread"`
+	Mentions    int       `json:"mentions,omitempty" out:"mentions,extra"`
+	Pinned      bool      `json:"pinned,omitempty" out:"pinned"`
+	Closed      bool      `json:"closed,omitempty" out:"closed"`
+	Hidden      bool      `json:"hidden,omitempty" out:"hidden,extra"`
+	CreatedAt   time.Time `json:"created_at,omitzero" out:"created,extra"`
+	Creator     string    `json:"creator,omitempty" out:"creator,extra"`
+	TopMessage  int       `json:"top_message,omitempty" out:"top_message,extra"`
+	IconEmojiID int64     `json:"icon_emoji_id,omitempty" out:"icon_emoji_id,extra"`
+}
+
+type InviteItem struct {
+	Link          string    `json:"link" out:"link"`
+	Title         string    `json:"title,omitempty" out:"title"`
+	Creator       string    `json:"creator,omitempty" out:"creator,extra"`
+	CreatedAt     time.Time `json:"created_at,omitzero" out:"created"`
+	ExpiresAt     time.Time `json:"expires_at,omitzero" out:"expires"`
+	UsageLimit    int       `json:"usage_limit,omitempty" out:"usage_limit,extra"`
+	Usage         int       `json:"usage" out:"usage"`
+	Requested     int       `json:"requested,omitempty" out:"requested,extra"`
+	RequestNeeded bool      `json:"request_needed,omitempty" out:"request_needed,extra"`
+	Permanent     bool      `json:"permanent,omitempty" out:"permanent,extra"`
+	Revoked       bool      `json:"revoked,omitempty" out:"revoked,extra"`
+}
+
+type InviteImporterItem struct {
+	PeerRef    string    `json:"peer_ref" out:"peer"`
+	Name       string    `json:"name" out:"name"`
+	Username   string    `json:"username,omitempty" out:"username"`
+	Date       time.Time `json:"date" out:"date"`
+	Requested  bool      `json:"requested,omitempty" out:"requested,extra"`
+	About      string    `json:"about,omitempty" out:"about,extra"`
+	ApprovedBy string    `json:"approved_by,omitempty" out:"approved_by,extra"`
+}
+
+type InvitePreview struct {
+	PeerRef       string `json:"peer_ref,omitempty" out:"peer"`
+	Title         string `json:"title" out:"title"`
+	Kind          string `json:"kind" out:"kind"`
+	About         string `json:"about,omitempty" out:"about,extra"`
+	Members       int    `json:"members" out:"members"`
+	RequestNeeded bool   `json:"request_needed,omitempty" out:"request_needed"`
+	Joined        bool   `json:"joined" out:"joined"`
+}
+
+type JoinResult struct {
+	PeerRef string `json:"peer_ref,omitempty" out:"peer"`
+	Title   string `json:"title" out:"title"`
+	Status  string `json:"status" out:"status"`
+}
+
+type PermissionsResult struct {
+	PeerRef string   `json:"peer_ref" out:"peer"`
+	Title   string   `json:"title" out:"title"`
+	Denied  []string `json:"denied" out:"denied"`
+}
+
+type ReadResult struct {
+	PeerRef   string `json:"peer_ref" out:"peer"`
+	Title     string `json:"title" out:"title"`
+	MaxID     int    `json:"max_id,omitempty" out:"max_id,extra"`
+	Unread    int    `json:"unread" out:"unread"`
+	Mentions  int    `json:"mentions" out:"mentions"`
+	Reactions int    `json:"reactions" out:"reactions"`
 }
 
 type ContactSearchItem struct {
-	DisplayName string `json:"display_name"`
-	Username    string `json:"username,omitempty"`
-	User        string `json:"user"`
+	DisplayName string `json:"display_name" out:"display_name"`
+	Username    string `json:"username,omitempty" out:"username"`
+	User        string `json:"user" out:"user"`
 }
 
 type MessageItem struct {
-	ID         int       `json:"id"`
-	Date       time.Time `json:"date"`
-	Text       string    `json:"text,omitempty"`
-	FromPeerID int64     `json:"from_peer_id,omitempty"`
-	PeerID     int64     `json:"peer_id,omitempty"`
-	Out        bool      `json:"out"`
-	Service    bool      `json:"service"`
+	ID         int             `json:"id" out:"id"`
+	Date       time.Time       `json:"date" out:"date"`
+	Text       string          `json:"text,omitempty" out:"text"`
+	FromPeerID int64           `json:"from_peer_id,omitempty" out:"from"`
+	FromName   string          `json:"from_name,omitempty" out:"from_name,extra"`
+	FromUser   string          `json:"from_username,omitempty" out:"from_username,extra"`
+	PeerID     int64           `json:"peer_id,omitempty" out:"peer_id,extra"`
+	ReplyToID  int             `json:"reply_to_id,omitempty" out:"reply_to,extra"`
+	TopicID    int             `json:"topic_id,omitempty" out:"topic,extra"`
+	Reactions  []ReactionCount `json:"reactions,omitempty" out:"reactions,extra"`
+	Buttons    []string        `json:"buttons,omitempty" out:"buttons,extra"`
+	Out        bool            `json:"out" out:"out,extra"`
+	Service    bool            `json:"service" out:"service,extra"`
+}
+
+type ReactionCount struct {
+	Reaction string `json:"reaction"`
+	Count    int    `json:"count"`
+	Chosen   bool   `json:"chosen,omitempty"`
+}
+
+func (r ReactionCount) String() string {
+	return r.Reaction + " " + strconv.Itoa(r.Count)
+}
+
+type CallbackAnswer struct {
+	Button  string `json:"button" out:"button"`
+	Message string `json:"message,omitempty" out:"message"`
+	Alert   bool   `json:"alert,omitempty" out:"alert,extra"`
+	URL     string `json:"url,omitempty" out:"url"`
+}
+
+type PollOptionItem struct {
+	Option  int         `json:"option" out:"option"`
+	Text    string      `json:"text" out:"text"`
+	Votes   int         `json:"votes" out:"votes"`
+	Percent int         `json:"percent" out:"percent"`
+	Chosen  bool        `json:"chosen,omitempty" out:"chosen"`
+	Correct bool        `json:"correct,omitempty" out:"correct,extra"`
+	Voters  []PollVoter `json:"voters,omitempty" out:"voters,extra"`
+}
+
+type PollVoter struct {
+	PeerRef  string    `json:"peer_ref"`
+	Name     string    `json:"name,omitempty"`
+	Username string    `json:"username,omitempty"`
+	Date     time.Time `json:"date"`
+}
+
+func (v PollVoter) String() string {
+	if v.Name != "" {
+		return v.Name
+	}
+	return v.PeerRef
+}
+
+type ReactionItem struct {
+	PeerRef  string    `json:"peer_ref" out:"peer"`
+	Name     string    `json:"name" out:"name"`
+	Username string    `json:"username,omitempty" out:"username"`
+	Reaction string    `json:"reaction" out:"reaction"`
+	Date     time.Time `json:"date" out:"date"`
+	Big      bool      `json:"big,omitempty" out:"big,extra"`
+	My       bool      `json:"my,omitempty" out:"my,extra"`
 }
 
 type SendResult struct {
-	OK        bool   `json:"ok"`
-	MessageID int    `json:"message_id,omitempty"`
-	Updates   string `json:"updates_type,omitempty"`
+	OK        bool   `json:"ok" out:"ok"`
+	MessageID int    `json:"message_id,omitempty" out:"message_id"`
+	PeerRef   string `json:"peer_ref,omitempty" out:"peer,extra"`
+	Updates   string `json:"updates_type,omitempty" out:"updates_type,extra"`
+}
+
+type BatchResult struct {
+	Line   int             `json:"line"`
+	Name   string          `json:"name,omitempty"`
+	Cmd    string          `json:"cmd"`
+	OK     bool            `json:"ok"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+type BotUpdate struct {
+	Type      string `json:"type" out:"type"`
+	PeerRef   string `json:"peer_ref,omitempty" out:"peer"`
+	From      string `json:"from,omitempty" out:"from"`
+	MessageID int    `json:"message_id,omitempty" out:"message_id,extra"`
+	Input     string `json:"input,omitempty" out:"input"`
+	Handler   string `json:"handler,omitempty" out:"handler"`
+	Error     string `json:"error,omitempty" out:"error"`
+}
+
+type CronRun struct {
+	Name      string    `json:"name" out:"name"`
+	PeerRef   string    `json:"peer_ref,omitempty" out:"peer"`
+	Slot      time.Time `json:"slot" out:"slot"`
+	MessageID int       `json:"message_id,omitempty" out:"message_id"`
+	Skipped   bool      `json:"skipped,omitempty" out:"skipped,extra"`
+	Error     string    `json:"error,omitempty" out:"error"`
 }